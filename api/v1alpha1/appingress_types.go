@@ -17,14 +17,21 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"slices"
+
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
-// IngressTemplate defines the template for creating an Ingress resource
+// IngressTemplate defines the template for creating an Ingress resource.
+// Annotation values, rule hosts and paths, and TLS hosts and secret names may contain Go templates,
+// e.g. "{{ .TargetNamespace }}.example.com", rendered for every target namespace. Available are
+// .TargetNamespace, .AppIngress.Name, .AppIngress.Namespace, .SourceNamespace.Labels with the labels
+// of the AppIngress namespace, and .Parameters with spec.parameters.
 type IngressTemplate struct {
 	// Standard object's metadata.
 	// +kubebuilder:validation:Required
@@ -36,30 +43,293 @@ type IngressTemplate struct {
 	Spec networkingv1.IngressSpec `json:"spec"`
 }
 
+// AdoptionPolicy defines how the controller treats an existing Ingress it did not create
+// +kubebuilder:validation:Enum=Never;IfUnowned;Always
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyNever refuses to modify an Ingress that was not created for this AppIngress
+	AdoptionPolicyNever AdoptionPolicy = "Never"
+
+	// AdoptionPolicyIfUnowned adopts an existing Ingress that is not managed by any AppIngress
+	AdoptionPolicyIfUnowned AdoptionPolicy = "IfUnowned"
+
+	// AdoptionPolicyAlways takes over an existing Ingress even if another AppIngress manages it
+	AdoptionPolicyAlways AdoptionPolicy = "Always"
+)
+
+// FieldConflictPolicy defines how the controller treats fields of the generated Ingress that are
+// managed by other field managers
+// +kubebuilder:validation:Enum=Force;Abort
+type FieldConflictPolicy string
+
+const (
+	// FieldConflictPolicyForce takes over conflicting fields, reverting changes made by others
+	FieldConflictPolicyForce FieldConflictPolicy = "Force"
+
+	// FieldConflictPolicyAbort leaves the Ingress untouched and reports the conflict
+	FieldConflictPolicyAbort FieldConflictPolicy = "Abort"
+)
+
+// BackendBridgeMode defines how backend Services are made available in the target namespaces
+// +kubebuilder:validation:Enum=ExternalName;EndpointSlice
+type BackendBridgeMode string
+
+const (
+	// BackendBridgeModeExternalName creates an ExternalName Service resolving to the backend Service
+	BackendBridgeModeExternalName BackendBridgeMode = "ExternalName"
+
+	// BackendBridgeModeEndpointSlice creates a Service without a selector whose EndpointSlices mirror
+	// those of the backend Service, for ingress controllers that do not support ExternalName backends
+	BackendBridgeModeEndpointSlice BackendBridgeMode = "EndpointSlice"
+)
+
+// OutputKind defines the kind of object generated from the template in every target namespace
+// +kubebuilder:validation:Enum=Ingress;HTTPRoute
+type OutputKind string
+
+const (
+	// OutputKindIngress generates a networking.k8s.io/v1 Ingress
+	OutputKindIngress OutputKind = "Ingress"
+
+	// OutputKindHTTPRoute generates a gateway.networking.k8s.io/v1 HTTPRoute translated from the template
+	OutputKindHTTPRoute OutputKind = "HTTPRoute"
+)
+
+// HTTPRouteOutput configures the HTTPRoutes generated when outputKind is HTTPRoute
+type HTTPRouteOutput struct {
+	// ParentRefs are the Gateways the HTTPRoutes attach to. A parent without a namespace refers to
+	// a Gateway in the target namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=32
+	ParentRefs []gatewayv1.ParentReference `json:"parentRefs"`
+}
+
+// TLSSecretSource configures where the Secrets referenced by the TLS entries of the template are
+// copied from
+type TLSSecretSource struct {
+	// Namespace holding the Secrets named by template.spec.tls[].secretName. Secrets outside the
+	// namespace of the AppIngress are only copied when their share-with annotation lists it.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
 // AppIngressSpec defines the desired state of AppIngress.
+// At least one of targetNamespace, targetNamespaces and namespaceSelector must be set;
+// the Ingress is created in the union of the namespaces they describe.
+// +kubebuilder:validation:XValidation:rule="has(self.targetNamespace) || has(self.targetNamespaces) || has(self.namespaceSelector)",message="one of targetNamespace, targetNamespaces or namespaceSelector must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.outputKind) || self.outputKind != 'HTTPRoute' || has(self.httpRoute)",message="httpRoute must be set when outputKind is HTTPRoute"
 type AppIngressSpec struct {
 	// Template defines the Ingress to be created
 	// +kubebuilder:validation:Required
 	Template IngressTemplate `json:"template"`
 
 	// TargetNamespace is the namespace where the Ingress will be created
-	// +kubebuilder:validation:Required
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// TargetNamespaces lists additional namespaces where the Ingress will be created
+	// +optional
+	// +listType=set
+	TargetNamespaces []string `json:"targetNamespaces,omitempty"`
+
+	// NamespaceSelector selects namespaces where the Ingress will be created by their labels.
+	// Ingresses are removed from namespaces that stop matching.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// AdoptionPolicy controls whether an existing Ingress that was not created for this
+	// AppIngress may be taken over. Defaults to Never.
+	// +optional
+	// +kubebuilder:default=Never
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// FieldConflictPolicy controls whether fields from the template that were changed by another
+	// field manager are taken over when the Ingress is applied. Defaults to Force.
+	// +optional
+	// +kubebuilder:default=Force
+	FieldConflictPolicy FieldConflictPolicy `json:"fieldConflictPolicy,omitempty"`
+
+	// TLSSecretSource copies the Secrets referenced by the TLS entries of the template into every
+	// target namespace and keeps the copies in sync. Secrets are expected to exist in the target
+	// namespaces when it is not set.
+	// +optional
+	TLSSecretSource *TLSSecretSource `json:"tlsSecretSource,omitempty"`
+
+	// BackendNamespace bridges the backend Services of the template into every target namespace
+	// with a Service of the same name routing to the Service in this namespace.
+	// It must be the namespace of the AppIngress. Backend Services are expected to exist in the
+	// target namespaces when it is not set.
+	// +optional
 	// +kubebuilder:validation:MinLength=1
-	TargetNamespace string `json:"targetNamespace"`
+	BackendNamespace string `json:"backendNamespace,omitempty"`
+
+	// BackendBridgeMode controls how backend Services are bridged when backendNamespace is set.
+	// Defaults to ExternalName.
+	// +optional
+	// +kubebuilder:default=ExternalName
+	BackendBridgeMode BackendBridgeMode `json:"backendBridgeMode,omitempty"`
+
+	// OutputKind selects the kind of object generated from the template. HTTPRoutes are translated
+	// from the rules of the template and require the controller to run with --enable-gateway-api.
+	// Defaults to Ingress.
+	// +optional
+	// +kubebuilder:default=Ingress
+	OutputKind OutputKind `json:"outputKind,omitempty"`
+
+	// HTTPRoute configures the generated HTTPRoutes when outputKind is HTTPRoute
+	// +optional
+	HTTPRoute *HTTPRouteOutput `json:"httpRoute,omitempty"`
+
+	// Parameters are made available to the template as .Parameters
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// ExplicitTargetNamespaces returns the namespaces listed by name in targetNamespace and
+// targetNamespaces, without duplicates. Namespaces matched by the selector are not included.
+func (s *AppIngressSpec) ExplicitTargetNamespaces() []string {
+	var namespaces []string
+	if s.TargetNamespace != "" {
+		namespaces = append(namespaces, s.TargetNamespace)
+	}
+	for _, namespace := range s.TargetNamespaces {
+		if namespace != "" && !slices.Contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// ResourceReference identifies a namespaced object created by the controller
+type ResourceReference struct {
+	// Namespace of the referenced object
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// Name of the referenced object
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// TargetStatus reports the outcome of reconciling the Ingress in a single target namespace
+type TargetStatus struct {
+	// Namespace is the target namespace
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// Status is True when the Ingress is up to date in the namespace
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status metav1.ConditionStatus `json:"status"`
+
+	// Reason is a machine-readable explanation of the status
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human-readable explanation of the status
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// BackendStatus reports whether a Service port used as a backend exists in a target namespace
+type BackendStatus struct {
+	// Namespace is the target namespace
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// Service is the name of the backend Service
+	// +kubebuilder:validation:Required
+	Service string `json:"service"`
+
+	// Port is the name or number of the backend Service port
+	// +kubebuilder:validation:Required
+	Port string `json:"port"`
+
+	// Status is True when the Service exists and exposes the port
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status metav1.ConditionStatus `json:"status"`
+
+	// Reason is a machine-readable explanation of the status
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human-readable explanation of the status
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // AppIngressStatus defines the observed state of AppIngress.
 type AppIngressStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Conditions represent the latest available observations of the AppIngress's current state
 	// +optional
 	// +patchMergeKey=type
 	// +patchStrategy=merge
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// Ingresses lists the Ingresses created for this AppIngress. Entries that no longer match
+	// the template name and target namespace are deleted by the controller.
+	// +optional
+	// +listType=atomic
+	Ingresses []ResourceReference `json:"ingresses,omitempty"`
+
+	// HTTPRoutes lists the HTTPRoutes created for this AppIngress when outputKind is HTTPRoute.
+	// Entries that are no longer desired are deleted by the controller.
+	// +optional
+	// +listType=atomic
+	HTTPRoutes []ResourceReference `json:"httpRoutes,omitempty"`
+
+	// TLSSecrets lists the Secrets copied into the target namespaces for this AppIngress. They are
+	// deleted together with the AppIngress.
+	// +optional
+	// +listType=atomic
+	TLSSecrets []ResourceReference `json:"tlsSecrets,omitempty"`
+
+	// BackendServices lists the Services bridged into the target namespaces for this AppIngress.
+	// They are deleted together with the AppIngress.
+	// +optional
+	// +listType=atomic
+	BackendServices []ResourceReference `json:"backendServices,omitempty"`
+
+	// Targets reports the outcome for every target namespace
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	Targets []TargetStatus `json:"targets,omitempty"`
+
+	// TargetCount is the number of target namespaces resolved from targetNamespace,
+	// targetNamespaces and namespaceSelector
+	// +optional
+	TargetCount int32 `json:"targetCount,omitempty"`
+
+	// Backends reports whether every backend Service port of the template exists in the target
+	// namespaces the Ingress is written to
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=service
+	// +listMapKey=port
+	Backends []BackendStatus `json:"backends,omitempty"`
+
+	// LoadBalancer mirrors the load-balancer status assigned to the generated Ingresses.
+	// With several target namespaces it holds the entries of all of them, without duplicates.
+	// +optional
+	LoadBalancer networkingv1.IngressLoadBalancerStatus `json:"loadBalancer,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
-// +kubebuilder:printcolumn:name="Target Namespace",type="string",JSONPath=".spec.targetNamespace"
+// +kubebuilder:printcolumn:name="Targets",type="integer",JSONPath=".status.targetCount"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+// +kubebuilder:printcolumn:name="Address",type="string",JSONPath=".status.loadBalancer.ingress[0].ip"
+// +kubebuilder:printcolumn:name="Hostname",type="string",JSONPath=".status.loadBalancer.ingress[0].hostname",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // AppIngress is the Schema for the appingresses API.
@@ -71,6 +341,16 @@ type AppIngress struct {
 	Status AppIngressStatus `json:"status,omitempty"`
 }
 
+// GetSpec returns the desired state of the AppIngress
+func (in *AppIngress) GetSpec() *AppIngressSpec {
+	return &in.Spec
+}
+
+// GetStatus returns the observed state of the AppIngress
+func (in *AppIngress) GetStatus() *AppIngressStatus {
+	return &in.Status
+}
+
 // +kubebuilder:object:root=true
 
 // AppIngressList contains a list of AppIngress.
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AppIngressPolicySpec allows AppIngresses in the source namespaces to generate Ingresses in the
+// target namespaces, optionally restricted to a set of hostnames. Policies are additive: an
+// AppIngress may target a namespace when at least one policy allows it.
+// +kubebuilder:validation:XValidation:rule="has(self.sourceNamespaces) || has(self.sourceNamespaceSelector)",message="one of sourceNamespaces or sourceNamespaceSelector must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.targetNamespaces) || has(self.targetNamespaceSelector)",message="one of targetNamespaces or targetNamespaceSelector must be set"
+type AppIngressPolicySpec struct {
+	// SourceNamespaces lists the namespaces of the AppIngresses the policy applies to
+	// +optional
+	// +listType=set
+	SourceNamespaces []string `json:"sourceNamespaces,omitempty"`
+
+	// SourceNamespaceSelector selects the namespaces of the AppIngresses the policy applies to
+	// by their labels
+	// +optional
+	SourceNamespaceSelector *metav1.LabelSelector `json:"sourceNamespaceSelector,omitempty"`
+
+	// TargetNamespaces lists the namespaces the AppIngresses may generate Ingresses in
+	// +optional
+	// +listType=set
+	TargetNamespaces []string `json:"targetNamespaces,omitempty"`
+
+	// TargetNamespaceSelector selects the namespaces the AppIngresses may generate Ingresses in
+	// by their labels
+	// +optional
+	TargetNamespaceSelector *metav1.LabelSelector `json:"targetNamespaceSelector,omitempty"`
+
+	// Hostnames lists the hosts the generated Ingresses may use. An entry starting with "*."
+	// allows every subdomain of the rest of the entry. All hosts are allowed when empty.
+	// +optional
+	// +listType=set
+	Hostnames []string `json:"hostnames,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// AppIngressPolicy is the Schema for the appingresspolicies API.
+type AppIngressPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AppIngressPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AppIngressPolicyList contains a list of AppIngressPolicy.
+type AppIngressPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AppIngressPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AppIngressPolicy{}, &AppIngressPolicyList{})
+}
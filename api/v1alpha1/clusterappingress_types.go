@@ -0,0 +1,81 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AppIngressObject is implemented by AppIngress and ClusterAppIngress, which share their spec and
+// status so the controller generates Ingresses from both the same way. A ClusterAppIngress has no
+// namespace.
+// +kubebuilder:object:generate=false
+type AppIngressObject interface {
+	client.Object
+
+	// GetSpec returns the desired state
+	GetSpec() *AppIngressSpec
+
+	// GetStatus returns the observed state
+	GetStatus() *AppIngressStatus
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Targets",type="integer",JSONPath=".status.targetCount"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+// +kubebuilder:printcolumn:name="Address",type="string",JSONPath=".status.loadBalancer.ingress[0].ip"
+// +kubebuilder:printcolumn:name="Hostname",type="string",JSONPath=".status.loadBalancer.ingress[0].hostname",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterAppIngress is the Schema for the clusterappingresses API. It is the cluster-scoped
+// counterpart of AppIngress for platform-wide routes, managed by cluster administrators.
+// Namespace consent and AppIngressPolicies do not apply to it, and its TLS Secrets and backend
+// Services may come from any namespace.
+type ClusterAppIngress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AppIngressSpec   `json:"spec,omitempty"`
+	Status AppIngressStatus `json:"status,omitempty"`
+}
+
+// GetSpec returns the desired state of the ClusterAppIngress
+func (in *ClusterAppIngress) GetSpec() *AppIngressSpec {
+	return &in.Spec
+}
+
+// GetStatus returns the observed state of the ClusterAppIngress
+func (in *ClusterAppIngress) GetStatus() *AppIngressStatus {
+	return &in.Status
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterAppIngressList contains a list of ClusterAppIngress.
+type ClusterAppIngressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterAppIngress `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterAppIngress{}, &ClusterAppIngressList{})
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Well-known labels and annotations set on objects generated from an AppIngress.
+// Cross-namespace owner references are not supported, so these markers are the
+// only link between a generated object and its source.
+const (
+	// ManagedByLabel marks objects created by the controller
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+
+	// ManagedByValue is the value of ManagedByLabel on objects created by the controller
+	ManagedByValue = "ingress-duplicator"
+
+	// FieldManager is the server-side apply field manager owning the fields the controller writes
+	FieldManager = "ingress-duplicator"
+
+	// SourceAnnotation references the AppIngress that produced the object in the "<namespace>/<name>"
+	// form, or the ClusterAppIngress by its name
+	SourceAnnotation = "ingress.example.com/source"
+
+	// SourceUIDAnnotation holds the UID of the source, which tells an object generated from a deleted
+	// source apart from one generated from a source that was recreated with the same name
+	SourceUIDAnnotation = "ingress.example.com/source-uid"
+
+	// SourceGenerationAnnotation holds the generation of the source the object was last generated from
+	SourceGenerationAnnotation = "ingress.example.com/source-generation"
+
+	// DuplicatorAnnotation references the ResourceDuplicator that produced the object in the
+	// "<namespace>/<name>" form. It takes the place of SourceAnnotation, so duplicated objects are
+	// never mistaken for objects generated from an AppIngress of the same name.
+	DuplicatorAnnotation = "ingress.example.com/duplicator"
+
+	// CopiedFromAnnotation references the object a copied TLS Secret or bridged Service was made from
+	// in the "<namespace>/<name>" form
+	CopiedFromAnnotation = "ingress.example.com/copied-from"
+)
+
+// MarkerAnnotations are the annotations the controller tracks the objects it generates with
+var MarkerAnnotations = []string{
+	SourceAnnotation,
+	SourceUIDAnnotation,
+	SourceGenerationAnnotation,
+	DuplicatorAnnotation,
+	CopiedFromAnnotation,
+}
+
+// ShareWithAnnotation lists the namespaces whose AppIngresses may copy a Secret into their target
+// namespaces, as a comma-separated list of namespaces or AcceptFromAll. Secrets in the namespace of
+// the AppIngress itself do not need it.
+const ShareWithAnnotation = "ingress.example.com/share-with"
+
+// Markers a target namespace uses to consent to receiving Ingresses when consent is required.
+// The label holds a single source namespace; the annotation may hold a comma-separated list of
+// source namespaces or AcceptFromAll, which label values cannot express.
+const (
+	// AcceptFromKey is the label and annotation listing the source namespaces a namespace accepts Ingresses from
+	AcceptFromKey = "ingress.example.com/accept-from"
+
+	// AcceptFromAll accepts Ingresses from AppIngresses in any namespace
+	AcceptFromAll = "*"
+)
+
+// IsManagedBy reports whether obj carries the markers of an object generated from the given source,
+// where source is the value of SourceAnnotation.
+func IsManagedBy(obj metav1.Object, source string) bool {
+	return IsManaged(obj) && obj.GetAnnotations()[SourceAnnotation] == source
+}
+
+// IsManaged reports whether obj carries the markers of an object generated by the controller
+func IsManaged(obj metav1.Object) bool {
+	return obj.GetLabels()[ManagedByLabel] == ManagedByValue && obj.GetAnnotations()[SourceAnnotation] != ""
+}
+
+// IsDuplicatedBy reports whether obj carries the markers of an object created by the given
+// ResourceDuplicator, where source is in the "<namespace>/<name>" form.
+func IsDuplicatedBy(obj metav1.Object, source string) bool {
+	return IsDuplicated(obj) && obj.GetAnnotations()[DuplicatorAnnotation] == source
+}
+
+// IsDuplicated reports whether obj carries the markers of an object created by a ResourceDuplicator
+func IsDuplicated(obj metav1.Object) bool {
+	return obj.GetLabels()[ManagedByLabel] == ManagedByValue && obj.GetAnnotations()[DuplicatorAnnotation] != ""
+}
+
+// AcceptsFrom reports whether the namespace consents to receiving Ingresses generated from
+// AppIngresses in the source namespace
+func AcceptsFrom(namespace metav1.Object, source string) bool {
+	if namespace.GetLabels()[AcceptFromKey] == source {
+		return true
+	}
+	annotation, ok := namespace.GetAnnotations()[AcceptFromKey]
+	return ok && listIncludes(annotation, source)
+}
+
+// SharedWith reports whether AppIngresses in the given namespace may copy the Secret
+func SharedWith(secret metav1.Object, namespace string) bool {
+	if secret.GetNamespace() == namespace {
+		return true
+	}
+	annotation, ok := secret.GetAnnotations()[ShareWithAnnotation]
+	return ok && listIncludes(annotation, namespace)
+}
+
+// listIncludes reports whether the comma-separated list contains the namespace or AcceptFromAll
+func listIncludes(list, namespace string) bool {
+	entries := strings.Split(list, ",")
+	for i := range entries {
+		entries[i] = strings.TrimSpace(entries[i])
+	}
+	return slices.Contains(entries, AcceptFromAll) || slices.Contains(entries, namespace)
+}
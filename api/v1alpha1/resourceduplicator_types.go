@@ -0,0 +1,159 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ResourceDuplicatorSpec defines the desired state of ResourceDuplicator.
+// At least one of targetNamespace, targetNamespaces and namespaceSelector must be set;
+// the object is created in the union of the namespaces they describe.
+// +kubebuilder:validation:XValidation:rule="has(self.targetNamespace) || has(self.targetNamespaces) || has(self.namespaceSelector)",message="one of targetNamespace, targetNamespaces or namespaceSelector must be set"
+type ResourceDuplicatorSpec struct {
+	// Template is the object created in every target namespace. It must set apiVersion, kind and
+	// metadata.name, and its kind must be namespaced and allowed by the controller. The namespace
+	// is replaced by the target namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:EmbeddedResource
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Template runtime.RawExtension `json:"template"`
+
+	// TargetNamespace is the namespace where the object will be created
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// TargetNamespaces lists additional namespaces where the object will be created
+	// +optional
+	// +listType=set
+	TargetNamespaces []string `json:"targetNamespaces,omitempty"`
+
+	// NamespaceSelector selects namespaces where the object will be created by their labels.
+	// Objects are removed from namespaces that stop matching.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// AdoptionPolicy controls whether an existing object that was not created for this
+	// ResourceDuplicator may be taken over. Defaults to Never.
+	// +optional
+	// +kubebuilder:default=Never
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// FieldConflictPolicy controls whether fields from the template that were changed by another
+	// field manager are taken over when the object is applied. Defaults to Force.
+	// +optional
+	// +kubebuilder:default=Force
+	FieldConflictPolicy FieldConflictPolicy `json:"fieldConflictPolicy,omitempty"`
+}
+
+// ExplicitTargetNamespaces returns the namespaces listed by name in targetNamespace and
+// targetNamespaces, without duplicates. Namespaces matched by the selector are not included.
+func (s *ResourceDuplicatorSpec) ExplicitTargetNamespaces() []string {
+	var namespaces []string
+	if s.TargetNamespace != "" {
+		namespaces = append(namespaces, s.TargetNamespace)
+	}
+	for _, namespace := range s.TargetNamespaces {
+		if namespace != "" && !slices.Contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// TypedResourceReference identifies a namespaced object of any kind created by the controller
+type TypedResourceReference struct {
+	// APIVersion of the referenced object
+	// +kubebuilder:validation:Required
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the referenced object
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+
+	// Namespace of the referenced object
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// Name of the referenced object
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// ResourceDuplicatorStatus defines the observed state of ResourceDuplicator.
+type ResourceDuplicatorStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the ResourceDuplicator's current state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// Resources lists the objects created for this ResourceDuplicator. Entries that no longer
+	// match the template and target namespaces are deleted by the controller.
+	// +optional
+	// +listType=atomic
+	Resources []TypedResourceReference `json:"resources,omitempty"`
+
+	// Targets reports the outcome for every target namespace
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	Targets []TargetStatus `json:"targets,omitempty"`
+
+	// TargetCount is the number of target namespaces resolved from targetNamespace,
+	// targetNamespaces and namespaceSelector
+	// +optional
+	TargetCount int32 `json:"targetCount,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.template.kind"
+// +kubebuilder:printcolumn:name="Targets",type="integer",JSONPath=".status.targetCount"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ResourceDuplicator is the Schema for the resourceduplicators API.
+type ResourceDuplicator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourceDuplicatorSpec   `json:"spec,omitempty"`
+	Status ResourceDuplicatorStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ResourceDuplicatorList contains a list of ResourceDuplicator.
+type ResourceDuplicatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourceDuplicator `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ResourceDuplicator{}, &ResourceDuplicatorList{})
+}
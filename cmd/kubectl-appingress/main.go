@@ -0,0 +1,442 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command kubectl-appingress is a kubectl plugin that links AppIngresses and ClusterAppIngresses
+// to the Ingresses generated from them. Install it on the PATH and run it as "kubectl appingress".
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	networkingv1apply "k8s.io/client-go/applyconfigurations/networking/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/render"
+	"github.com/rafal-jan/ingress-duplicator/internal/sourceref"
+)
+
+const usage = `Usage: kubectl appingress <command> [flags]
+
+Commands:
+  list             List AppIngresses and ClusterAppIngresses with the objects they generate
+  trace <ingress>  Show the AppIngress or ClusterAppIngress an Ingress was generated from
+  diff <ingress>   Compare the fields the controller owns on an Ingress with the template of its
+                   source, exits 1 when they differ
+  orphans          List managed Ingresses whose source is gone
+
+Flags:
+  --kubeconfig string    Path to the kubeconfig file
+  --context string       Name of the kubeconfig context to use
+  -n, --namespace string Namespace of the command, defaults to the namespace of the context
+  -A, --all-namespaces   List objects in all namespaces (list and orphans)
+`
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+}
+
+// errDiffer is returned by diff when the Ingress differs from its template, so the plugin exits
+// with status 1 like kubectl diff
+var errDiffer = errors.New("differences found")
+
+// options are the flags shared by all commands
+type options struct {
+	kubeconfig    string
+	context       string
+	namespace     string
+	allNamespaces bool
+}
+
+// plugin runs the commands against a cluster
+type plugin struct {
+	client.Client
+	out       io.Writer
+	namespace string
+	all       bool
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := run(context.Background(), os.Args[1], os.Args[2:]); err != nil {
+		if !errors.Is(err, errDiffer) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run parses the flags of the command and runs it
+func run(ctx context.Context, command string, args []string) error {
+	var opts options
+	fs := flag.NewFlagSet("kubectl appingress "+command, flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(fs.Output(), usage) }
+	fs.StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	fs.StringVar(&opts.context, "context", "", "Name of the kubeconfig context to use")
+	fs.StringVar(&opts.namespace, "namespace", "", "Namespace of the command")
+	fs.StringVar(&opts.namespace, "n", "", "Namespace of the command")
+	fs.BoolVar(&opts.allNamespaces, "all-namespaces", false, "List objects in all namespaces")
+	fs.BoolVar(&opts.allNamespaces, "A", false, "List objects in all namespaces")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var expected int
+	switch command {
+	case "list", "orphans":
+	case "trace", "diff":
+		expected = 1
+	default:
+		return fmt.Errorf("unknown command %q, run kubectl appingress --help for usage", command)
+	}
+	if len(positional) != expected {
+		return fmt.Errorf("%s expects %d argument(s), got %d", command, expected, len(positional))
+	}
+
+	p, err := newPlugin(opts)
+	if err != nil {
+		return err
+	}
+	switch command {
+	case "list":
+		return p.list(ctx)
+	case "trace":
+		return p.trace(ctx, positional[0])
+	case "diff":
+		return p.diff(ctx, positional[0])
+	default:
+		return p.orphans(ctx)
+	}
+}
+
+// parseInterspersed parses flags that may follow positional arguments, e.g. "trace web -n team-a",
+// and returns the positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// newPlugin connects to the cluster selected by the kubeconfig flags
+func newPlugin(opts options) (*plugin, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = opts.kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: opts.context}
+	overrides.Context.Namespace = opts.namespace
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	return &plugin{Client: c, out: os.Stdout, namespace: namespace, all: opts.allNamespaces}, nil
+}
+
+// listOptions selects the namespace of the command, or all namespaces
+func (p *plugin) listOptions() []client.ListOption {
+	if p.all {
+		return nil
+	}
+	return []client.ListOption{client.InNamespace(p.namespace)}
+}
+
+// list prints the AppIngresses in the namespace and all ClusterAppIngresses with their targets
+func (p *plugin) list(ctx context.Context) error {
+	appIngresses := &ingressv1alpha1.AppIngressList{}
+	if err := p.List(ctx, appIngresses, p.listOptions()...); err != nil {
+		return err
+	}
+	clusterAppIngresses := &ingressv1alpha1.ClusterAppIngressList{}
+	if err := p.List(ctx, clusterAppIngresses); err != nil {
+		return err
+	}
+	var sources []ingressv1alpha1.AppIngressObject
+	for i := range appIngresses.Items {
+		sources = append(sources, &appIngresses.Items[i])
+	}
+	for i := range clusterAppIngresses.Items {
+		sources = append(sources, &clusterAppIngresses.Items[i])
+	}
+	if len(sources) == 0 {
+		fmt.Fprintln(p.out, "No AppIngresses found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tOUTPUT\tTARGETS\tREADY")
+	for _, source := range sources {
+		kind := source.GetSpec().OutputKind
+		refs := source.GetStatus().Ingresses
+		if kind == ingressv1alpha1.OutputKindHTTPRoute {
+			refs = source.GetStatus().HTTPRoutes
+		} else {
+			kind = ingressv1alpha1.OutputKindIngress
+		}
+		targets := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			targets = append(targets, ref.Namespace+"/"+ref.Name)
+		}
+		if len(targets) == 0 {
+			targets = append(targets, "<none>")
+		}
+		key := client.ObjectKeyFromObject(source)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", sourceref.Describe(key), kind, strings.Join(targets, ","),
+			readyStatus(source))
+	}
+	return w.Flush()
+}
+
+// readyStatus returns the status of the Ready condition, or Unknown when it is not reported yet
+func readyStatus(source ingressv1alpha1.AppIngressObject) string {
+	condition := meta.FindStatusCondition(source.GetStatus().Conditions, "Ready")
+	if condition == nil {
+		return "Unknown"
+	}
+	return string(condition.Status) + " (" + condition.Reason + ")"
+}
+
+// getIngress returns the Ingress named either "<name>" in the namespace of the command or "<namespace>/<name>"
+func (p *plugin) getIngress(ctx context.Context, name string) (*networkingv1.Ingress, error) {
+	key := types.NamespacedName{Namespace: p.namespace, Name: name}
+	if namespace, ingressName, ok := strings.Cut(name, "/"); ok {
+		key = types.NamespacedName{Namespace: namespace, Name: ingressName}
+	}
+	ingress := &networkingv1.Ingress{}
+	if err := p.Get(ctx, key, ingress); err != nil {
+		return nil, err
+	}
+	if _, ok := sourceref.Key(ingress); !ok {
+		return nil, fmt.Errorf("the Ingress %s is not managed by %s", key, ingressv1alpha1.ManagedByValue)
+	}
+	return ingress, nil
+}
+
+// trace prints the source of the Ingress and whether the Ingress is up to date with it
+func (p *plugin) trace(ctx context.Context, name string) error {
+	ingress, err := p.getIngress(ctx, name)
+	if err != nil {
+		return err
+	}
+	key, _ := sourceref.Key(ingress)
+	annotations := ingress.Annotations
+
+	w := tabwriter.NewWriter(p.out, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(w, "Ingress:\t%s\n", client.ObjectKeyFromObject(ingress))
+	fmt.Fprintf(w, "Source:\t%s\n", sourceref.Describe(key))
+	fmt.Fprintf(w, "Source UID:\t%s\n", valueOrNone(annotations[ingressv1alpha1.SourceUIDAnnotation]))
+	fmt.Fprintf(w, "Generated from:\tgeneration %s\n",
+		valueOrNone(annotations[ingressv1alpha1.SourceGenerationAnnotation]))
+
+	reason, err := sourceref.OrphanReason(ctx, p, ingress)
+	if err != nil {
+		return err
+	}
+	switch reason {
+	case sourceref.ReasonSourceNotFound:
+		fmt.Fprintf(w, "State:\tOrphaned, the source does not exist\n")
+	case sourceref.ReasonSourceRecreated:
+		fmt.Fprintf(w, "State:\tOrphaned, the source was recreated and no longer generates the Ingress\n")
+	default:
+		source, err := sourceref.Get(ctx, p, ingress)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Source generation:\t%d\n", source.GetGeneration())
+		fmt.Fprintf(w, "Source ready:\t%s\n", readyStatus(source))
+	}
+	return w.Flush()
+}
+
+// valueOrNone returns the value, or "<none>" when it is empty
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+// generated holds the parts of an Ingress the controller writes
+type generated struct {
+	Labels      map[string]string
+	Annotations map[string]string
+	Spec        networkingv1.IngressSpec
+}
+
+// diff prints the differences between the Ingress and the template of its source rendered for the
+// namespace of the Ingress
+func (p *plugin) diff(ctx context.Context, name string) error {
+	ingress, err := p.getIngress(ctx, name)
+	if err != nil {
+		return err
+	}
+	source, err := sourceref.Get(ctx, p, ingress)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			key, _ := sourceref.Key(ingress)
+			return fmt.Errorf("%s does not exist, the Ingress is orphaned", sourceref.Describe(key))
+		}
+		return err
+	}
+	if source.GetSpec().OutputKind == ingressv1alpha1.OutputKindHTTPRoute {
+		return fmt.Errorf("%s generates HTTPRoutes, not Ingresses",
+			sourceref.Describe(client.ObjectKeyFromObject(source)))
+	}
+
+	var sourceLabels map[string]string
+	if source.GetNamespace() != "" {
+		namespace := &corev1.Namespace{}
+		if err := p.Get(ctx, client.ObjectKey{Name: source.GetNamespace()}, namespace); err != nil {
+			return err
+		}
+		sourceLabels = namespace.Labels
+	}
+	d, err := ingressDiff(source, sourceLabels, ingress)
+	if err != nil {
+		return err
+	}
+	if d != "" {
+		fmt.Fprintf(p.out, "Ingress %s differs from %s (-template +live):\n%s",
+			client.ObjectKeyFromObject(ingress), sourceref.Describe(client.ObjectKeyFromObject(source)), d)
+		return errDiffer
+	}
+	fmt.Fprintf(p.out, "Ingress %s matches %s\n", client.ObjectKeyFromObject(ingress),
+		sourceref.Describe(client.ObjectKeyFromObject(source)))
+	return nil
+}
+
+// ingressDiff compares the fields of the Ingress owned by the controller's field manager with the
+// template of its source rendered for the namespace of the Ingress, and returns the differences or
+// an empty string. Fields set by other managers are ignored, fields another manager took over from
+// the controller show up as missing from the live Ingress.
+func ingressDiff(source ingressv1alpha1.AppIngressObject, sourceLabels map[string]string,
+	ingress *networkingv1.Ingress) (string, error) {
+	vars := render.NewVariables(source, sourceLabels, ingress.Namespace)
+	template, errs := render.Template(&source.GetSpec().Template, vars, field.NewPath("spec", "template"))
+	if len(errs) > 0 {
+		return "", errs.ToAggregate()
+	}
+	want := generated{
+		Labels:      maps.Clone(template.Labels),
+		Annotations: maps.Clone(template.Annotations),
+		Spec:        template.Spec,
+	}
+	if want.Labels == nil {
+		want.Labels = map[string]string{}
+	}
+	if want.Annotations == nil {
+		want.Annotations = map[string]string{}
+	}
+	want.Labels[ingressv1alpha1.ManagedByLabel] = ingressv1alpha1.ManagedByValue
+	want.Annotations[ingressv1alpha1.SourceAnnotation] = ingress.Annotations[ingressv1alpha1.SourceAnnotation]
+	want.Annotations[ingressv1alpha1.SourceUIDAnnotation] = string(source.GetUID())
+	want.Annotations[ingressv1alpha1.SourceGenerationAnnotation] = fmt.Sprint(source.GetGeneration())
+
+	// The extracted apply configuration has the same JSON form as the Ingress
+	owned, err := networkingv1apply.ExtractIngress(ingress, ingressv1alpha1.FieldManager)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(owned)
+	if err != nil {
+		return "", err
+	}
+	live := &networkingv1.Ingress{}
+	if err := json.Unmarshal(data, live); err != nil {
+		return "", err
+	}
+	got := generated{Labels: live.Labels, Annotations: live.Annotations, Spec: live.Spec}
+	return cmp.Diff(want, got, cmpopts.EquateEmpty()), nil
+}
+
+// orphans prints the managed Ingresses whose source no longer exists or no longer generates them
+func (p *plugin) orphans(ctx context.Context) error {
+	ingresses := &networkingv1.IngressList{}
+	opts := append(p.listOptions(), client.MatchingLabels{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue})
+	if err := p.List(ctx, ingresses, opts...); err != nil {
+		return err
+	}
+
+	var rows []string
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		reason, err := sourceref.OrphanReason(ctx, p, ingress)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			key, _ := sourceref.Key(ingress)
+			rows = append(rows, fmt.Sprintf("%s\t%s\t%s", client.ObjectKeyFromObject(ingress), sourceref.Describe(key), reason))
+		}
+	}
+	if len(rows) == 0 {
+		fmt.Fprintln(p.out, "No orphaned Ingresses found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "INGRESS\tSOURCE\tREASON")
+	for _, row := range rows {
+		fmt.Fprintln(w, row)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+// ownedFields are the fields the controller applies to an Ingress generated from a template setting
+// a label and the rules
+const ownedFields = `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{},"f:team":{}},` +
+	`"f:annotations":{"f:ingress.example.com/source":{},"f:ingress.example.com/source-uid":{},` +
+	`"f:ingress.example.com/source-generation":{}}},"f:spec":{"f:rules":{}}}`
+
+var _ = Describe("kubectl-appingress", func() {
+	var (
+		ctx        context.Context
+		appIngress *ingressv1alpha1.AppIngress
+		ingress    *networkingv1.Ingress
+		out        *strings.Builder
+	)
+
+	newPluginWith := func(objs ...client.Object) *plugin {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		return &plugin{Client: c, out: out, namespace: "team-a"}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		out = &strings.Builder{}
+		appIngress = &ingressv1alpha1.AppIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", UID: "uid-1", Generation: 2},
+			Spec: ingressv1alpha1.AppIngressSpec{
+				Template: ingressv1alpha1.IngressTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"team": "a"}},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{Host: "{{ .TargetNamespace }}.example.com"}},
+					},
+				},
+				TargetNamespace: "team-b",
+			},
+			Status: ingressv1alpha1.AppIngressStatus{
+				Ingresses: []ingressv1alpha1.ResourceReference{{Namespace: "team-b", Name: "web"}},
+				Conditions: []metav1.Condition{{
+					Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready",
+				}},
+			},
+		}
+		ingress = &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "team-b",
+				Labels: map[string]string{
+					ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue,
+					"team":                         "a",
+				},
+				Annotations: map[string]string{
+					ingressv1alpha1.SourceAnnotation:           "team-a/web",
+					ingressv1alpha1.SourceUIDAnnotation:        "uid-1",
+					ingressv1alpha1.SourceGenerationAnnotation: "2",
+				},
+				ManagedFields: []metav1.ManagedFieldsEntry{{
+					Manager:    ingressv1alpha1.FieldManager,
+					Operation:  metav1.ManagedFieldsOperationApply,
+					APIVersion: "networking.k8s.io/v1",
+					FieldsType: "FieldsV1",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(ownedFields)},
+				}},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{Host: "team-b.example.com"}},
+			},
+		}
+	})
+
+	Describe("readyStatus", func() {
+		It("should render the Ready condition with its reason", func() {
+			Expect(readyStatus(appIngress)).To(Equal("True (Ready)"))
+		})
+
+		It("should report Unknown before the condition is set", func() {
+			appIngress.Status.Conditions = nil
+			Expect(readyStatus(appIngress)).To(Equal("Unknown"))
+		})
+	})
+
+	Describe("list", func() {
+		It("should print every source with its generated objects", func() {
+			clusterAppIngress := &ingressv1alpha1.ClusterAppIngress{ObjectMeta: metav1.ObjectMeta{Name: "status-page"}}
+			Expect(newPluginWith(appIngress, clusterAppIngress).list(ctx)).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(3))
+			Expect(strings.Fields(lines[0])).To(Equal([]string{"SOURCE", "OUTPUT", "TARGETS", "READY"}))
+			Expect(lines[1]).To(MatchRegexp(`^AppIngress team-a/web\s+Ingress\s+team-b/web\s+True \(Ready\)$`))
+			Expect(lines[2]).To(MatchRegexp(`^ClusterAppIngress status-page\s+Ingress\s+<none>\s+Unknown$`))
+		})
+	})
+
+	Describe("ingressDiff", func() {
+		It("should report no differences for an up to date Ingress", func() {
+			Expect(ingressDiff(appIngress, nil, ingress)).To(BeEmpty())
+		})
+
+		It("should ignore fields owned by other managers", func() {
+			className := "nginx"
+			ingress.Spec.IngressClassName = &className
+			ingress.Labels["added-by"] = "someone-else"
+			ingress.Annotations["example.com/added-by"] = "someone-else"
+			Expect(ingressDiff(appIngress, nil, ingress)).To(BeEmpty())
+		})
+
+		It("should report changes to owned fields", func() {
+			ingress.Spec.Rules[0].Host = "changed.example.com"
+			d, err := ingressDiff(appIngress, nil, ingress)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d).To(ContainSubstring(`"team-b.example.com"`))
+			Expect(d).To(ContainSubstring(`"changed.example.com"`))
+		})
+
+		It("should report fields another manager took over as missing", func() {
+			ingress.ManagedFields[0].FieldsV1.Raw = []byte(`{"f:metadata":{"f:labels":{` +
+				`"f:app.kubernetes.io/managed-by":{},"f:team":{}},"f:annotations":{"f:ingress.example.com/source":{},` +
+				`"f:ingress.example.com/source-uid":{},"f:ingress.example.com/source-generation":{}}}}`)
+			d, err := ingressDiff(appIngress, nil, ingress)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d).To(ContainSubstring("team-b.example.com"))
+		})
+
+		It("should report a template generation the Ingress was not generated from", func() {
+			appIngress.Generation = 3
+			d, err := ingressDiff(appIngress, nil, ingress)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d).To(ContainSubstring(ingressv1alpha1.SourceGenerationAnnotation))
+		})
+	})
+
+	Describe("diff", func() {
+		It("should refuse Ingresses the controller does not manage", func() {
+			unmanaged := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "team-a"}}
+			err := newPluginWith(unmanaged).diff(ctx, "legacy")
+			Expect(err).To(MatchError(ContainSubstring("not managed")))
+		})
+
+		It("should print the differences and fail when the Ingress drifted", func() {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+			Expect(newPluginWith(appIngress, ingress, namespace).diff(ctx, "team-b/web")).To(Succeed())
+			Expect(out.String()).To(Equal("Ingress team-b/web matches AppIngress team-a/web\n"))
+
+			out.Reset()
+			ingress.Spec.Rules[0].Host = "changed.example.com"
+			err := newPluginWith(appIngress, ingress, namespace).diff(ctx, "team-b/web")
+			Expect(err).To(MatchError(errDiffer))
+			Expect(out.String()).To(HavePrefix("Ingress team-b/web differs from AppIngress team-a/web (-template +live):"))
+		})
+
+		It("should report Ingresses whose source is gone", func() {
+			err := newPluginWith(ingress).diff(ctx, "team-b/web")
+			Expect(err).To(MatchError(ContainSubstring("AppIngress team-a/web does not exist")))
+		})
+	})
+
+	Describe("trace and orphans", func() {
+		It("should trace an Ingress to its source", func() {
+			Expect(newPluginWith(appIngress, ingress).trace(ctx, "team-b/web")).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("AppIngress team-a/web"))
+			Expect(out.String()).To(MatchRegexp(`Source ready:\s+True \(Ready\)`))
+		})
+
+		It("should list Ingresses whose source is gone", func() {
+			p := newPluginWith(ingress)
+			p.all = true
+			Expect(p.orphans(ctx)).To(Succeed())
+			Expect(out.String()).To(MatchRegexp(`team-b/web\s+AppIngress team-a/web\s+SourceNotFound`))
+		})
+
+		It("should report when nothing is orphaned", func() {
+			p := newPluginWith(appIngress, ingress)
+			p.all = true
+			Expect(p.orphans(ctx)).To(Succeed())
+			Expect(out.String()).To(Equal("No orphaned Ingresses found\n"))
+		})
+	})
+})
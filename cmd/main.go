@@ -17,16 +17,20 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -36,9 +40,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
 	"github.com/rafal-jan/ingress-duplicator/internal/controller"
+	"github.com/rafal-jan/ingress-duplicator/internal/policy"
+	webhookingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -51,6 +58,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1.Install(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -63,6 +71,11 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var deniedTargetNamespaces, clusterDomain string
+	var enforcePolicy, requireConsent, includeUnmanagedHosts, enableGatewayAPI bool
+	var defaultIngressClass, defaultTLSSecretPattern, defaultAnnotations, defaultsConfigMap string
+	var duplicatorKinds, orphanPolicy string
+	var orphanSweepInterval time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -81,6 +94,36 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&deniedTargetNamespaces, "denied-target-namespaces",
+		strings.Join(webhookingressv1alpha1.DefaultDeniedNamespaces, ","),
+		"Comma-separated list of namespaces that AppIngresses and ResourceDuplicators may not target.")
+	flag.BoolVar(&enforcePolicy, "enforce-appingress-policy", false,
+		"If set, AppIngresses may only target the namespaces and hosts allowed by an AppIngressPolicy.")
+	flag.BoolVar(&requireConsent, "require-namespace-consent", false,
+		"If set, Ingresses are only written into namespaces that accept them through the "+
+			ingressv1alpha1.AcceptFromKey+" label or annotation.")
+	flag.StringVar(&clusterDomain, "cluster-domain", "cluster.local",
+		"The DNS domain of the cluster, used to address backend Services bridged into target namespaces.")
+	flag.BoolVar(&includeUnmanagedHosts, "host-conflicts-include-unmanaged", false,
+		"If set, hosts routed by Ingresses not managed by the controller also block AppIngresses created after them.")
+	flag.BoolVar(&enableGatewayAPI, "enable-gateway-api", false,
+		"If set, AppIngresses may generate Gateway API HTTPRoutes. Requires the Gateway API CRDs to be installed.")
+	flag.StringVar(&defaultIngressClass, "default-ingress-class", "",
+		"Ingress class set on AppIngress templates that do not specify one.")
+	flag.StringVar(&defaultTLSSecretPattern, "default-tls-secret-pattern", "",
+		"Pattern naming TLS secrets left empty in AppIngress templates. "+
+			"{name} is replaced with the Ingress name and {host} with the first TLS host.")
+	flag.StringVar(&defaultAnnotations, "default-annotations", "",
+		"Comma-separated key=value annotations added to AppIngress templates that do not set them.")
+	flag.StringVar(&defaultsConfigMap, "defaults-configmap", "",
+		"ConfigMap in the namespace/name form whose values override the AppIngress template default flags.")
+	flag.StringVar(&duplicatorKinds, "duplicator-allowed-kinds", strings.Join(controller.DefaultDuplicatorKinds, ","),
+		"Comma-separated list of kinds in the <apiVersion>/<kind> form that ResourceDuplicators may create. "+
+			"The controller needs RBAC permissions for every listed kind.")
+	flag.StringVar(&orphanPolicy, "orphan-policy", string(controller.OrphanPolicyReport),
+		"What to do with generated Ingresses whose AppIngress no longer exists: report or delete.")
+	flag.DurationVar(&orphanSweepInterval, "orphan-sweep-interval", 10*time.Minute,
+		"How often to look for generated Ingresses whose AppIngress no longer exists.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -202,13 +245,112 @@ func main() {
 		os.Exit(1)
 	}
 
+	var evaluator *policy.Evaluator
+	if enforcePolicy {
+		evaluator = &policy.Evaluator{Reader: mgr.GetClient()}
+	}
+
 	if err = (&controller.AppIngressReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorderFor("appingress-controller"),
+		RequireConsent:        requireConsent,
+		Policy:                evaluator,
+		IncludeUnmanagedHosts: includeUnmanagedHosts,
+		ClusterDomain:         clusterDomain,
+		GatewayAPI:            enableGatewayAPI,
+		DeniedNamespaces:      splitList(deniedTargetNamespaces),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AppIngress")
 		os.Exit(1)
 	}
+	allowedKinds, err := controller.ParseKinds(splitList(duplicatorKinds))
+	if err != nil {
+		setupLog.Error(err, "invalid --duplicator-allowed-kinds")
+		os.Exit(1)
+	}
+	if err = (&controller.ResourceDuplicatorReconciler{
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		Recorder:         mgr.GetEventRecorderFor("resourceduplicator-controller"),
+		AllowedKinds:     allowedKinds,
+		RequireConsent:   requireConsent,
+		Policy:           evaluator,
+		DeniedNamespaces: splitList(deniedTargetNamespaces),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ResourceDuplicator")
+		os.Exit(1)
+	}
+	sweeperPolicy, err := controller.ParseOrphanPolicy(orphanPolicy)
+	if err != nil {
+		setupLog.Error(err, "invalid --orphan-policy")
+		os.Exit(1)
+	}
+	if orphanSweepInterval <= 0 {
+		setupLog.Error(nil, "--orphan-sweep-interval must be positive")
+		os.Exit(1)
+	}
+	if err = mgr.Add(&controller.OrphanSweeper{
+		Client:     mgr.GetClient(),
+		Recorder:   mgr.GetEventRecorderFor("orphan-sweeper"),
+		Policy:     sweeperPolicy,
+		Interval:   orphanSweepInterval,
+		GatewayAPI: enableGatewayAPI,
+	}); err != nil {
+		setupLog.Error(err, "unable to add orphan sweeper to manager")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		annotations, err := webhookingressv1alpha1.ParseAnnotations(defaultAnnotations)
+		if err != nil {
+			setupLog.Error(err, "invalid --default-annotations")
+			os.Exit(1)
+		}
+		defaults := webhookingressv1alpha1.AppIngressDefaults{
+			IngressClassName:     defaultIngressClass,
+			TLSSecretNamePattern: defaultTLSSecretPattern,
+			Annotations:          annotations,
+		}
+		if defaultsConfigMap != "" {
+			namespace, name, ok := strings.Cut(defaultsConfigMap, "/")
+			if !ok || namespace == "" || name == "" {
+				setupLog.Error(nil, "invalid --defaults-configmap, expected namespace/name", "value", defaultsConfigMap)
+				os.Exit(1)
+			}
+			// The cache is not started yet, so read the ConfigMap directly from the API server
+			defaults, err = webhookingressv1alpha1.LoadDefaultsFromConfigMap(context.Background(), mgr.GetAPIReader(),
+				types.NamespacedName{Namespace: namespace, Name: name}, defaults)
+			if err != nil {
+				setupLog.Error(err, "unable to load AppIngress defaults", "configmap", defaultsConfigMap)
+				os.Exit(1)
+			}
+		}
+		appIngressWebhookOptions := webhookingressv1alpha1.AppIngressWebhookOptions{
+			DeniedNamespaces:      splitList(deniedTargetNamespaces),
+			Defaults:              defaults,
+			Policy:                evaluator,
+			IncludeUnmanagedHosts: includeUnmanagedHosts,
+			GatewayAPI:            enableGatewayAPI,
+		}
+		if err = webhookingressv1alpha1.SetupAppIngressWebhookWithManager(mgr, appIngressWebhookOptions); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "AppIngress")
+			os.Exit(1)
+		}
+		if err = webhookingressv1alpha1.SetupClusterAppIngressWebhookWithManager(mgr, appIngressWebhookOptions); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterAppIngress")
+			os.Exit(1)
+		}
+		if err = webhookingressv1alpha1.SetupResourceDuplicatorWebhookWithManager(mgr,
+			webhookingressv1alpha1.ResourceDuplicatorWebhookOptions{
+				DeniedNamespaces: splitList(deniedTargetNamespaces),
+				AllowedKinds:     allowedKinds,
+				Policy:           evaluator,
+			}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ResourceDuplicator")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -242,3 +384,14 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList parses a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -18,37 +18,101 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/hostclaim"
+	"github.com/rafal-jan/ingress-duplicator/internal/policy"
 )
 
 // AppIngressReconciler reconciles a AppIngress object
 type AppIngressReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// RequireConsent limits the target namespaces to those that accept Ingresses from the namespace
+	// of the AppIngress through the accept-from label or annotation
+	RequireConsent bool
+
+	// Policy restricts the target namespaces and hosts according to the AppIngressPolicies.
+	// AppIngresses may target any namespace when it is nil.
+	Policy *policy.Evaluator
+
+	// IncludeUnmanagedHosts also treats hosts routed by Ingresses not managed by the controller as
+	// claims, so AppIngresses created after them cannot take their traffic over
+	IncludeUnmanagedHosts bool
+
+	// ClusterDomain is the DNS domain of the cluster used to address bridged backend Services,
+	// cluster.local when empty
+	ClusterDomain string
+
+	// GatewayAPI enables generating and watching Gateway API HTTPRoutes, which requires their CRDs
+	GatewayAPI bool
+
+	// DeniedNamespaces lists namespaces AppIngresses and ClusterAppIngresses may never write into,
+	// whether they list them by name or select them by labels
+	DeniedNamespaces []string
+
+	// indexedHosts looks up host claims through the host index registered in SetupWithManager
+	indexedHosts bool
+
+	generations generationTracker
 }
 
 // +kubebuilder:rbac:groups=ingress.example.com,resources=appingresses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ingress.example.com,resources=appingresses/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ingress.example.com,resources=appingresses/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ingress.example.com,resources=clusterappingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ingress.example.com,resources=clusterappingresses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ingress.example.com,resources=clusterappingresses/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ingress.example.com,resources=appingresspolicies,verbs=get;list;watch
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Condition Types for AppIngress
 const (
-	ConditionTypeNamespaceValid = "NamespaceValid"
-	ConditionTypeIngressCreated = "IngressCreated"
+	ConditionTypeNamespaceValid   = "NamespaceValid"
+	ConditionTypeTemplateRendered = "TemplateRendered"
+	ConditionTypeIngressCreated   = "IngressCreated"
+	ConditionTypeIngressConflict  = "IngressConflict"
+	ConditionTypePolicyAllowed    = "PolicyAllowed"
+	ConditionTypeHostConflict     = "HostConflict"
+	ConditionTypeTLSSecretsSynced = "TLSSecretsSynced"
+	ConditionTypeBackendsSynced   = "BackendsSynced"
+	ConditionTypeBackendsResolved = "BackendsResolved"
+	ConditionTypeReady            = "Ready"
 )
 
 // Finalizer for AppIngress cleanup
@@ -56,13 +120,29 @@ const (
 	finalizerName = "ingress.example.com/cleanup"
 )
 
-// Reconcile handles the reconciliation loop for AppIngress resources
+// fieldManager owns the fields of the generated Ingresses that come from the template
+const (
+	fieldManager = ingressv1alpha1.FieldManager
+)
+
+// Field indexes for looking up AppIngresses by their explicitly listed target
+// namespaces, by the name of the generated Ingress and by the hosts it routes.
+// The host index is shared with the host claim checks, which register it.
+const (
+	targetNamespaceIndexKey = ".spec.targetNamespace"
+	templateNameIndexKey    = ".spec.template.metadata.name"
+	hostIndexKey            = hostclaim.HostIndexKey
+)
+
+// Reconcile handles the reconciliation loop for AppIngress and ClusterAppIngress resources. Both
+// share their spec and status, and AppIngresses always have a namespace, so requests without one
+// are for ClusterAppIngresses.
 func (r *AppIngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Reconciling AppIngress")
 
 	// Get AppIngress
-	appIngress := &ingressv1alpha1.AppIngress{}
+	appIngress := newAppIngressObject(req.NamespacedName)
 	if err := r.Get(ctx, req.NamespacedName, appIngress); err != nil {
 		if apierrors.IsNotFound(err) {
 			return ctrl.Result{}, nil
@@ -71,126 +151,938 @@ func (r *AppIngressReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	// Handle deletion
-	if !appIngress.DeletionTimestamp.IsZero() {
+	if !appIngress.GetDeletionTimestamp().IsZero() {
 		if controllerutil.ContainsFinalizer(appIngress, finalizerName) {
-			logger.Info("Cleaning up associated Ingress", "namespace", appIngress.Spec.TargetNamespace)
-
-			// Try to delete the Ingress
-			ingress := &networkingv1.Ingress{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      appIngress.Spec.Template.Name,
-					Namespace: appIngress.Spec.TargetNamespace,
-				},
-			}
-			if err := r.Delete(ctx, ingress); err != nil {
-				if !apierrors.IsNotFound(err) {
-					logger.Error(err, "Failed to delete Ingress during cleanup")
-					return ctrl.Result{}, err
-				}
-				// If the Ingress is already gone, we can proceed with removing the finalizer
-				logger.Info("Ingress already deleted or not found")
-			}
-
-			// Remove finalizer to allow AppIngress deletion
-			controllerutil.RemoveFinalizer(appIngress, finalizerName)
-			if err := r.Update(ctx, appIngress); err != nil {
-				return ctrl.Result{}, err
-			}
-			logger.Info("Cleanup completed successfully")
-			return ctrl.Result{}, nil
+			logger.Info("Cleaning up associated Ingresses", "count", len(appIngress.GetStatus().Ingresses))
+		}
+		if err := finalize(ctx, r.Client, r.Recorder, appIngress, r.cleanupSteps(ctx, appIngress)...); err != nil {
+			return ctrl.Result{}, err
 		}
-		// Finalizer already removed, nothing to do
+		r.generations.forget(appIngress.GetUID())
 		return ctrl.Result{}, nil
 	}
 
-	// Add finalizer if it doesn't exist
-	if !controllerutil.ContainsFinalizer(appIngress, finalizerName) {
-		controllerutil.AddFinalizer(appIngress, finalizerName)
-		if err := r.Update(ctx, appIngress); err != nil {
+	if err := ensureFinalizer(ctx, r.Client, appIngress); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.generations.start(appIngress)
+
+	// Skip target namespaces the AppIngress may not write into. AppIngressPolicies restrict
+	// namespaces, ClusterAppIngresses are not subject to them and need no consent either, since
+	// they are created by cluster administrators.
+	source := appIngressSource(appIngress)
+	gate := targetGate{
+		requireConsent: r.RequireConsent,
+		denials: func(ctx context.Context, namespaces []string) (map[string]string, error) {
+			return r.targetDenials(ctx, appIngress, namespaces)
+		},
+	}
+	switch {
+	case appIngress.GetNamespace() == "":
+		gate.notEnforced = "AppIngressPolicies do not apply to ClusterAppIngresses"
+	case r.Policy == nil:
+		gate.notEnforced = "AppIngressPolicies are not enforced"
+	}
+	resolved, err := resolveTargets(ctx, r.Client, r.Recorder, source, gate)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if resolved == nil {
+		setReadyCondition(appIngress, nil)
+		appIngress.GetStatus().ObservedGeneration = appIngress.GetGeneration()
+		if err := r.Status().Update(ctx, appIngress); err != nil {
+			logger.Error(err, "Failed to update AppIngress status")
 			return ctrl.Result{}, err
 		}
-		// After adding finalizer, continue with reconciliation to set initial conditions
+		return ctrl.Result{}, nil
 	}
+	namespaces := resolved.allowed
 
-	// Check if target namespace exists
-	targetNs := &corev1.Namespace{}
-	if err := r.Get(ctx, client.ObjectKey{Name: appIngress.Spec.TargetNamespace}, targetNs); err != nil {
-		if apierrors.IsNotFound(err) {
-			logger.Error(err, "Target namespace not found", "namespace", appIngress.Spec.TargetNamespace)
-			meta.SetStatusCondition(&appIngress.Status.Conditions, metav1.Condition{
-				Type:    ConditionTypeNamespaceValid,
-				Status:  metav1.ConditionFalse,
-				Reason:  "NotFound",
-				Message: "Target namespace does not exist",
-			})
-			if err := r.Status().Update(ctx, appIngress); err != nil {
-				logger.Error(err, "Failed to update AppIngress status")
-				return ctrl.Result{}, err
+	// The oldest claim of a host and path wins. Nothing is written while an older AppIngress or
+	// Ingress routes the same host and path, and Ingresses generated before are removed below.
+	finder := &hostclaim.Finder{Reader: r.Client, IncludeUnmanaged: r.IncludeUnmanagedHosts, Indexed: r.indexedHosts}
+	hostConflicts, err := finder.Conflicts(ctx, appIngress, namespaces, true)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if setHostConflictCondition(appIngress, hostConflicts) && len(hostConflicts) > 0 {
+		logger.Info("Hosts are claimed by someone else", "conflicts", len(hostConflicts))
+		r.Recorder.Event(appIngress, corev1.EventTypeWarning, "HostConflict",
+			meta.FindStatusCondition(appIngress.GetStatus().Conditions, ConditionTypeHostConflict).Message)
+	}
+	var blocked []string
+	if len(hostConflicts) > 0 {
+		blocked, namespaces = namespaces, nil
+	}
+
+	sourceLabels, err := r.sourceNamespaceLabels(ctx, appIngress)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Create/Update the Ingress in every target namespace
+	var keep, keepRoutes, keepSecrets, keepServices []ingressv1alpha1.ResourceReference
+	// Generated objects of the other output kind are not kept, so switching kinds replaces them
+	kind := outputKind(appIngress)
+	kept := &keep
+	if kind == ingressv1alpha1.OutputKindHTTPRoute {
+		kept = &keepRoutes
+	}
+	var conflicts []*ingressConflictError
+	var errs, renderErrs, secretErrs, backendErrs []error
+	if err := invalidBackendNamespace(appIngress); err != nil {
+		backendErrs = append(backendErrs, err)
+	}
+	var loadBalancer networkingv1.IngressLoadBalancerStatus
+	var pending []string
+	var backends []ingressv1alpha1.BackendStatus
+	var targets []ingressv1alpha1.TargetStatus
+	for _, namespace := range namespaces {
+		ref := ingressRef(appIngress, namespace)
+		target := ingressv1alpha1.TargetStatus{Namespace: namespace}
+
+		rendered, err := renderAppIngress(appIngress, sourceLabels, namespace)
+		if err != nil {
+			// Keep what was generated from the last template that rendered until the template is fixed
+			logger.Info("Failed to render template", "namespace", namespace, "error", err.Error())
+			*kept = append(*kept, ref)
+			keepSecrets = append(keepSecrets, refsInNamespace(appIngress.GetStatus().TLSSecrets, namespace)...)
+			keepServices = append(keepServices, refsInNamespace(appIngress.GetStatus().BackendServices, namespace)...)
+			renderErrs = append(renderErrs, err)
+			target.Status, target.Reason, target.Message = metav1.ConditionFalse, "RenderFailed", err.Error()
+			targets = append(targets, target)
+			continue
+		}
+
+		// Secrets go first so the Ingress does not come up without its certificate
+		secretRefs, syncErrs := r.reconcileTLSSecrets(ctx, rendered, namespace)
+		keepSecrets = append(keepSecrets, secretRefs...)
+		secretErrs = append(secretErrs, syncErrs...)
+		serviceRefs, syncErrs := r.reconcileBackends(ctx, rendered, namespace)
+		keepServices = append(keepServices, serviceRefs...)
+		backendErrs = append(backendErrs, syncErrs...)
+		resolved, err := r.resolveBackends(ctx, rendered, namespace)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		backends = append(backends, resolved...)
+
+		var conflictErr *ingressConflictError
+		var ingress *networkingv1.Ingress
+		var route *gatewayv1.HTTPRoute
+		var output client.Object
+		var result controllerutil.OperationResult
+		if kind == ingressv1alpha1.OutputKindHTTPRoute {
+			route, result, err = r.reconcileHTTPRoute(ctx, rendered, ref)
+			output = route
+		} else {
+			ingress, result, err = r.reconcileIngress(ctx, rendered, ref)
+			output = ingress
+		}
+		if errors.As(err, &conflictErr) {
+			// Leave the existing object untouched, the watch requeues once it changes
+			logger.Info("Generated object is not managed by this AppIngress", "kind", kind, "object", ref,
+				"reason", conflictErr.reason)
+			if ingress != nil {
+				ingressOperations.WithLabelValues(operationConflict).Inc()
+			}
+			r.Recorder.Event(appIngress, corev1.EventTypeWarning, "IngressConflict", conflictErr.Error())
+			r.Recorder.Eventf(output, corev1.EventTypeWarning, "IngressConflict",
+				"%s cannot apply the %s: %s", describeSource(sourceKey(appIngress)), kind, conflictErr.Error())
+			conflicts = append(conflicts, conflictErr)
+			target.Status, target.Reason, target.Message = metav1.ConditionFalse, conflictErr.reason, conflictErr.Error()
+		} else if err != nil {
+			logger.Error(err, "Failed to create/update generated object", "kind", kind, "object", ref)
+			// The object may exist even though the call failed, keep track of it
+			*kept = append(*kept, ref)
+			errs = append(errs, err)
+			target.Status, target.Reason, target.Message = metav1.ConditionFalse, "Error",
+				fmt.Sprintf("Failed to create/update %s: %v", kind, err)
+		} else {
+			*kept = append(*kept, ref)
+			target.Status, target.Reason, target.Message = metav1.ConditionTrue, "Created",
+				fmt.Sprintf("%s created/updated successfully", kind)
+			r.recordResult(appIngress, kind, output, result)
+			if route != nil {
+				if !routeAccepted(route) {
+					pending = append(pending, namespace)
+				}
+				targets = append(targets, target)
+				continue
+			}
+			if len(ingress.Status.LoadBalancer.Ingress) == 0 {
+				pending = append(pending, namespace)
+			}
+			for _, lb := range ingress.Status.LoadBalancer.Ingress {
+				if !slices.ContainsFunc(loadBalancer.Ingress, func(existing networkingv1.IngressLoadBalancerIngress) bool {
+					return equality.Semantic.DeepEqual(existing, lb)
+				}) {
+					loadBalancer.Ingress = append(loadBalancer.Ingress, lb)
+				}
 			}
-			return ctrl.Result{}, nil
 		}
+		targets = append(targets, target)
+	}
+	targets = append(targets, resolved.skipped(source)...)
+	for _, namespace := range blocked {
+		targets = append(targets, ingressv1alpha1.TargetStatus{
+			Namespace: namespace,
+			Status:    metav1.ConditionFalse,
+			Reason:    "HostConflict",
+			Message:   "Another owner claims a host of the Ingress",
+		})
+	}
+	if setTemplateCondition(appIngress, renderErrs) && len(renderErrs) > 0 {
+		r.Recorder.Event(appIngress, corev1.EventTypeWarning, "TemplateRenderFailed",
+			meta.FindStatusCondition(appIngress.GetStatus().Conditions, ConditionTypeTemplateRendered).Message)
+	}
+	counts := resolved.countOutcomes(len(namespaces) - len(renderErrs))
+	counts.blocked, counts.unrendered = len(blocked), len(renderErrs)
+	setOutputConditions(source, ConditionTypeIngressCreated, ConditionTypeIngressConflict, "Ingress", "AppIngress",
+		counts, conflicts, errs)
+	notConfigured := ""
+	if appIngress.GetSpec().TLSSecretSource == nil {
+		notConfigured = "TLS Secrets are not copied"
+	}
+	if setSyncCondition(appIngress, ConditionTypeTLSSecretsSynced, "TLS Secrets are copied into every target namespace",
+		notConfigured, secretErrs) && len(secretErrs) > 0 {
+		logger.Info("Failed to copy TLS Secrets", "errors", len(secretErrs))
+		r.Recorder.Event(appIngress, corev1.EventTypeWarning, "TLSSecretSyncFailed",
+			meta.FindStatusCondition(appIngress.GetStatus().Conditions, ConditionTypeTLSSecretsSynced).Message)
+	}
+	notConfigured = ""
+	if appIngress.GetSpec().BackendNamespace == "" {
+		notConfigured = "Backend Services are not bridged"
+	}
+	if setSyncCondition(appIngress, ConditionTypeBackendsSynced, "Backend Services are bridged into every target namespace",
+		notConfigured, backendErrs) && len(backendErrs) > 0 {
+		logger.Info("Failed to bridge backend Services", "errors", len(backendErrs))
+		r.Recorder.Event(appIngress, corev1.EventTypeWarning, "BackendSyncFailed",
+			meta.FindStatusCondition(appIngress.GetStatus().Conditions, ConditionTypeBackendsSynced).Message)
+	}
+	// The Ingress is written either way, the Service watch updates the condition once the Service appears
+	if setBackendsResolvedCondition(appIngress, backends) && !meta.IsStatusConditionTrue(appIngress.GetStatus().Conditions,
+		ConditionTypeBackendsResolved) {
+		logger.Info("Backend Services not found")
+		r.Recorder.Event(appIngress, corev1.EventTypeWarning, "BackendNotFound",
+			meta.FindStatusCondition(appIngress.GetStatus().Conditions, ConditionTypeBackendsResolved).Message)
+	}
+	setReadyCondition(appIngress, pending)
+	if len(namespaces) > 0 && len(resolved.missing) == 0 && len(resolved.notPermitted) == 0 && len(resolved.denials) == 0 &&
+		len(conflicts) == 0 && len(errs) == 0 && len(renderErrs) == 0 && len(secretErrs) == 0 && len(backendErrs) == 0 {
+		r.generations.applied(appIngress)
+	}
+	// Objects that cannot be copied until their source or target changes are picked up by the
+	// Secret and Service watches, anything else is retried
+	for _, err := range slices.Concat(secretErrs, backendErrs) {
+		var syncErr *syncError
+		if !errors.As(err, &syncErr) {
+			errs = append(errs, err)
+		}
+	}
+
+	// Remove objects that are no longer desired, e.g. after a rename, a target namespace change
+	// or when a namespace stops matching the selector. This happens after the new Ingresses are
+	// in place to avoid interrupting traffic.
+	status := appIngress.GetStatus()
+	copies := func(kind string, newObj func() client.Object) func(refs,
+		keep []ingressv1alpha1.ResourceReference) ([]ingressv1alpha1.ResourceReference, error) {
+		return func(refs, keep []ingressv1alpha1.ResourceReference) ([]ingressv1alpha1.ResourceReference, error) {
+			return r.cleanupCopies(ctx, appIngress, kind, newObj, refs, keep)
+		}
+	}
+	remaining, cleanupErr := pruneStale(ctx, r.Recorder, appIngress, "Ingresses", status.Ingresses, keep,
+		func(refs, keep []ingressv1alpha1.ResourceReference) ([]ingressv1alpha1.ResourceReference, error) {
+			return r.cleanupIngresses(ctx, appIngress, refs, keep)
+		}, compareRefs)
+	if cleanupErr != nil {
+		errs = append(errs, cleanupErr)
+	}
+	// HTTPRoutes recorded while the Gateway API was enabled are kept until it is enabled again
+	if !r.GatewayAPI {
+		keepRoutes = slices.Concat(status.HTTPRoutes, keepRoutes)
+	}
+	remainingRoutes, cleanupErr := pruneStale(ctx, r.Recorder, appIngress, "HTTPRoutes", status.HTTPRoutes,
+		keepRoutes, copies("HTTPRoute", newHTTPRoute), compareRefs)
+	if cleanupErr != nil {
+		errs = append(errs, cleanupErr)
+	}
+	remainingSecrets, cleanupErr := pruneStale(ctx, r.Recorder, appIngress, "TLS Secrets", status.TLSSecrets,
+		keepSecrets, copies("Secret", newSecret), compareRefs)
+	if cleanupErr != nil {
+		errs = append(errs, cleanupErr)
+	}
+	remainingServices, cleanupErr := pruneStale(ctx, r.Recorder, appIngress, "backend Services",
+		status.BackendServices, keepServices,
+		func(refs, keep []ingressv1alpha1.ResourceReference) ([]ingressv1alpha1.ResourceReference, error) {
+			return r.cleanupBackends(ctx, appIngress, refs, keep)
+		}, compareRefs)
+	if cleanupErr != nil {
+		errs = append(errs, cleanupErr)
+	}
+	slices.SortFunc(targets, func(a, b ingressv1alpha1.TargetStatus) int {
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
+	status.Ingresses = remaining
+	status.HTTPRoutes = remainingRoutes
+	status.TLSSecrets = remainingSecrets
+	status.BackendServices = remainingServices
+	status.Targets = targets
+	status.TargetCount = int32(len(targets))
+	status.Backends = backends
+	status.LoadBalancer = loadBalancer
+	status.ObservedGeneration = appIngress.GetGeneration()
+
+	if err := r.Status().Update(ctx, appIngress); err != nil {
+		logger.Error(err, "Failed to update AppIngress status")
 		return ctrl.Result{}, err
 	}
+	if len(errs) > 0 {
+		return ctrl.Result{}, kerrors.NewAggregate(errs)
+	}
 
-	// Set namespace valid condition
-	meta.SetStatusCondition(&appIngress.Status.Conditions, metav1.Condition{
-		Type:    ConditionTypeNamespaceValid,
+	logger.Info("Reconciliation completed successfully")
+	return ctrl.Result{}, nil
+}
+
+// targetDenials returns a message for every target namespace the AppIngress may not write into:
+// the denied namespaces and, when policies are enforced, the namespaces or hosts no AppIngressPolicy
+// allows for an AppIngress
+func (r *AppIngressReconciler) targetDenials(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	namespaces []string) (map[string]string, error) {
+	denials := map[string]string{}
+	namespaced, isNamespaced := appIngress.(*ingressv1alpha1.AppIngress)
+	if !isNamespaced {
+		addDeniedNamespaces(denials, r.DeniedNamespaces, namespaces, "a ClusterAppIngress")
+		return denials, nil
+	}
+	if r.Policy != nil {
+		policyDenials, err := r.Policy.Denials(ctx, namespaced, namespaces)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(denials, policyDenials)
+	}
+	addDeniedNamespaces(denials, r.DeniedNamespaces, namespaces, "an AppIngress")
+	return denials, nil
+}
+
+// setHostConflictCondition reports whether an older AppIngress or Ingress already routes a host and
+// path of the AppIngress. It reports whether the condition changed.
+func setHostConflictCondition(appIngress ingressv1alpha1.AppIngressObject, conflicts []hostclaim.Conflict) bool {
+	condition := metav1.Condition{
+		Type:    ConditionTypeHostConflict,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Unique",
+		Message: "No other owner claims the hosts of the Ingress",
+	}
+	if len(conflicts) > 0 {
+		messages := make([]string, 0, len(conflicts))
+		for _, conflict := range conflicts {
+			messages = append(messages, fmt.Sprintf("host %s is already claimed by %s", conflict.Claim, conflict.Owner))
+		}
+		condition.Status, condition.Reason = metav1.ConditionTrue, "HostClaimed"
+		condition.Message = strings.Join(messages, "; ")
+	}
+	return setCondition(appIngress, condition)
+}
+
+// setReadyCondition summarizes the other conditions into the Ready condition. The AppIngress is
+// ready once every target namespace exists and is allowed, its template renders, no one else claims
+// its hosts, its TLS Secrets and backends are in sync, every generated object is in place and routes
+// to existing Services, and the ingress controller has assigned the Ingresses an address or a Gateway
+// accepted the HTTPRoutes. pending lists the target namespaces still waiting for one.
+// Otherwise the reason and message of the first unhealthy condition are surfaced.
+func setReadyCondition(appIngress ingressv1alpha1.AppIngressObject, pending []string) {
+	condition, healthy := summarizeReady(appIngressSource(appIngress), metav1.Condition{
+		Type:    ConditionTypeReady,
 		Status:  metav1.ConditionTrue,
-		Reason:  "Valid",
-		Message: "Target namespace exists",
-	})
+		Reason:  "AddressAssigned",
+		Message: "Ingress has been assigned an address",
+	},
+		readyCheck{ConditionTypeNamespaceValid, metav1.ConditionTrue},
+		readyCheck{ConditionTypeTemplateRendered, metav1.ConditionTrue},
+		readyCheck{ConditionTypePolicyAllowed, metav1.ConditionTrue},
+		readyCheck{ConditionTypeHostConflict, metav1.ConditionFalse},
+		readyCheck{ConditionTypeIngressConflict, metav1.ConditionFalse},
+		readyCheck{ConditionTypeTLSSecretsSynced, metav1.ConditionTrue},
+		readyCheck{ConditionTypeBackendsSynced, metav1.ConditionTrue},
+		readyCheck{ConditionTypeIngressCreated, metav1.ConditionTrue},
+		readyCheck{ConditionTypeBackendsResolved, metav1.ConditionTrue},
+	)
+	switch {
+	case !healthy:
+	case outputKind(appIngress) == ingressv1alpha1.OutputKindHTTPRoute && len(pending) > 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "RoutePending"
+		condition.Message = "Waiting for a Gateway to accept the HTTPRoute in: " + strings.Join(pending, ", ")
+	case outputKind(appIngress) == ingressv1alpha1.OutputKindHTTPRoute:
+		condition.Reason, condition.Message = "RouteAccepted", "HTTPRoute has been accepted by a Gateway"
+	case len(pending) > 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "AddressPending"
+		condition.Message = "Waiting for the ingress controller to assign an address in: " + strings.Join(pending, ", ")
+	}
+	setCondition(appIngress, condition)
+}
 
-	// Create/Update Ingress
+// setCondition sets the condition on the AppIngress, stamped with the generation it was computed from.
+// It reports whether the condition changed.
+func setCondition(appIngress ingressv1alpha1.AppIngressObject, condition metav1.Condition) bool {
+	return appIngressSource(appIngress).setCondition(condition)
+}
+
+// reconcileIngress applies the Ingress in a single target namespace and returns it together with
+// the operation that was performed. Server-side apply only claims the fields set by the template,
+// so labels, annotations and spec fields added by other controllers are preserved.
+func (r *AppIngressReconciler) reconcileIngress(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	ref ingressv1alpha1.ResourceReference) (*networkingv1.Ingress, controllerutil.OperationResult, error) {
+	existing := &networkingv1.Ingress{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return existing, controllerutil.OperationResultNone, err
+		}
+	}
+	// Refuse to take over an Ingress that belongs to someone else
+	if err := checkOwnership(appIngress, ingressv1alpha1.OutputKindIngress, existing); err != nil {
+		return existing, controllerutil.OperationResultNone, err
+	}
+
+	// Owner references cannot cross namespaces, the source is tracked through labels and annotations
 	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "Ingress",
+		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      appIngress.Spec.Template.Name,
-			Namespace: appIngress.Spec.TargetNamespace,
+			Name:        ref.Name,
+			Namespace:   ref.Namespace,
+			Labels:      managedLabels(appIngress),
+			Annotations: managedAnnotations(appIngress),
 		},
+		Spec: *appIngress.GetSpec().Template.Spec.DeepCopy(),
+	}
+	result, err := r.applyOutput(ctx, appIngress, ingressv1alpha1.OutputKindIngress, ingress, existing)
+	if err != nil {
+		return existing, result, err
+	}
+	return ingress, result, nil
+}
+
+// applyOutput applies the object generated for the AppIngress and returns the operation that was
+// performed, comparing it to the existing object. Fields managed by someone else are only taken
+// over when the field conflict policy allows it.
+func (r *AppIngressReconciler) applyOutput(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	kind ingressv1alpha1.OutputKind, obj, existing client.Object) (controllerutil.OperationResult, error) {
+	opts := []client.PatchOption{client.FieldOwner(fieldManager)}
+	if appIngress.GetSpec().FieldConflictPolicy != ingressv1alpha1.FieldConflictPolicyAbort {
+		opts = append(opts, client.ForceOwnership)
+	}
+	if err := r.Patch(ctx, obj, client.Apply, opts...); err != nil {
+		if apierrors.IsConflict(err) {
+			return controllerutil.OperationResultNone, &ingressConflictError{
+				reason: "FieldConflict",
+				message: fmt.Sprintf("%s %s/%s has fields managed by someone else, "+
+					"set fieldConflictPolicy to Force to take them over: %v", kind, obj.GetNamespace(), obj.GetName(), err),
+			}
+		}
+		return controllerutil.OperationResultNone, err
+	}
+
+	switch {
+	case existing.GetResourceVersion() == "":
+		return controllerutil.OperationResultCreated, nil
+	case existing.GetResourceVersion() != obj.GetResourceVersion():
+		return controllerutil.OperationResultUpdated, nil
 	}
+	return controllerutil.OperationResultNone, nil
+}
+
+// recordResult emits matching events on the AppIngress and the generated object and counts the
+// operation when an Ingress was created or updated. Unchanged objects are not reported.
+func (r *AppIngressReconciler) recordResult(appIngress ingressv1alpha1.AppIngressObject, kind ingressv1alpha1.OutputKind,
+	obj client.Object, result controllerutil.OperationResult) {
+	var verb, operation string
+	switch result {
+	case controllerutil.OperationResultCreated:
+		verb, operation = "Created", operationCreate
+	case controllerutil.OperationResultUpdated:
+		verb, operation = "Updated", operationUpdate
+	default:
+		return
+	}
+	if kind == ingressv1alpha1.OutputKindIngress {
+		ingressOperations.WithLabelValues(operation).Inc()
+	}
+	reason := string(kind) + verb
+	r.Recorder.Eventf(appIngress, corev1.EventTypeNormal, reason, "%s %s %s/%s",
+		verb, kind, obj.GetNamespace(), obj.GetName())
+	r.Recorder.Eventf(obj, corev1.EventTypeNormal, reason, "%s from %s",
+		verb, describeSource(sourceKey(appIngress)))
+}
+
+// ingressConflictError is returned when the generated Ingress or HTTPRoute exists but may not be modified
+type ingressConflictError struct {
+	reason  string
+	message string
+}
+
+func (e *ingressConflictError) Error() string {
+	return e.message
+}
 
-	// Create or update ingress - skip owner reference for cross-namespace objects
-	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, ingress, func() error {
-		// Update ingress spec and metadata
-		ingress.Labels = appIngress.Spec.Template.Labels
-		ingress.Annotations = appIngress.Spec.Template.Annotations
-		ingress.Spec = appIngress.Spec.Template.Spec
+// checkOwnership verifies that the existing Ingress or HTTPRoute may be written according to the
+// adoption policy of the AppIngress. Objects that do not exist yet are always writable. Objects
+// created by a ResourceDuplicator are never taken over, whatever the adoption policy.
+func checkOwnership(appIngress ingressv1alpha1.AppIngressObject, kind ingressv1alpha1.OutputKind, obj client.Object) error {
+	if obj.GetResourceVersion() == "" || ingressv1alpha1.IsManagedBy(obj, sourceKey(appIngress)) {
 		return nil
-	}); err != nil {
-		logger.Error(err, "Failed to create/update Ingress")
-		meta.SetStatusCondition(&appIngress.Status.Conditions, metav1.Condition{
-			Type:    ConditionTypeIngressCreated,
-			Status:  metav1.ConditionFalse,
-			Reason:  "Error",
-			Message: "Failed to create/update Ingress: " + err.Error(),
-		})
-		if err := r.Status().Update(ctx, appIngress); err != nil {
-			logger.Error(err, "Failed to update AppIngress status")
-			return ctrl.Result{}, err
+	}
+	if ingressv1alpha1.IsDuplicated(obj) {
+		return &ingressConflictError{
+			reason: "OwnedByOther",
+			message: fmt.Sprintf("%s %s/%s is managed by ResourceDuplicator %s", kind, obj.GetNamespace(), obj.GetName(),
+				obj.GetAnnotations()[ingressv1alpha1.DuplicatorAnnotation]),
 		}
-		return ctrl.Result{}, err
 	}
 
-	// Update success condition
-	meta.SetStatusCondition(&appIngress.Status.Conditions, metav1.Condition{
-		Type:    ConditionTypeIngressCreated,
-		Status:  metav1.ConditionTrue,
-		Reason:  "Created",
-		Message: "Ingress created/updated successfully",
+	policy := appIngress.GetSpec().AdoptionPolicy
+	if ingressv1alpha1.IsManaged(obj) {
+		if policy == ingressv1alpha1.AdoptionPolicyAlways {
+			return nil
+		}
+		return &ingressConflictError{
+			reason: "OwnedByOther",
+			message: fmt.Sprintf("%s %s/%s is managed by %s", kind, obj.GetNamespace(), obj.GetName(),
+				describeSource(obj.GetAnnotations()[ingressv1alpha1.SourceAnnotation])),
+		}
+	}
+	if policy == ingressv1alpha1.AdoptionPolicyIfUnowned || policy == ingressv1alpha1.AdoptionPolicyAlways {
+		return nil
+	}
+	return &ingressConflictError{
+		reason: "NotManaged",
+		message: fmt.Sprintf("%s %s/%s already exists and is not managed by the controller, "+
+			"set adoptionPolicy to adopt it", kind, obj.GetNamespace(), obj.GetName()),
+	}
+}
+
+// sourceKey returns the value of the source annotation for objects generated from the AppIngress,
+// which is just the name for a ClusterAppIngress
+func sourceKey(appIngress ingressv1alpha1.AppIngressObject) string {
+	if appIngress.GetNamespace() == "" {
+		return appIngress.GetName()
+	}
+	return appIngress.GetNamespace() + "/" + appIngress.GetName()
+}
+
+// describeSource names the kind and key of the object a source annotation refers to
+func describeSource(source string) string {
+	if strings.Contains(source, "/") {
+		return "AppIngress " + source
+	}
+	return "ClusterAppIngress " + source
+}
+
+// newAppIngressObject returns an empty AppIngress, or a ClusterAppIngress when the key has no namespace
+func newAppIngressObject(key types.NamespacedName) ingressv1alpha1.AppIngressObject {
+	if key.Namespace == "" {
+		return &ingressv1alpha1.ClusterAppIngress{}
+	}
+	return &ingressv1alpha1.AppIngress{}
+}
+
+// listAppIngresses returns the AppIngresses and ClusterAppIngresses matching the options. Options
+// selecting a namespace only match AppIngresses.
+func (r *AppIngressReconciler) listAppIngresses(ctx context.Context,
+	opts ...client.ListOption) ([]ingressv1alpha1.AppIngressObject, error) {
+	appIngresses := &ingressv1alpha1.AppIngressList{}
+	if err := r.List(ctx, appIngresses, opts...); err != nil {
+		return nil, err
+	}
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	clusterAppIngresses := &ingressv1alpha1.ClusterAppIngressList{}
+	if listOpts.Namespace == "" {
+		if err := r.List(ctx, clusterAppIngresses, opts...); err != nil {
+			return nil, err
+		}
+	}
+	objs := make([]ingressv1alpha1.AppIngressObject, 0, len(appIngresses.Items)+len(clusterAppIngresses.Items))
+	for i := range appIngresses.Items {
+		objs = append(objs, &appIngresses.Items[i])
+	}
+	for i := range clusterAppIngresses.Items {
+		objs = append(objs, &clusterAppIngresses.Items[i])
+	}
+	return objs, nil
+}
+
+// ingressRef returns the reference of the Ingress generated in the given target namespace
+func ingressRef(appIngress ingressv1alpha1.AppIngressObject, namespace string) ingressv1alpha1.ResourceReference {
+	return ingressv1alpha1.ResourceReference{
+		Namespace: namespace,
+		Name:      appIngress.GetSpec().Template.Name,
+	}
+}
+
+// compareRefs orders references by namespace and name
+func compareRefs(a, b ingressv1alpha1.ResourceReference) int {
+	if c := strings.Compare(a.Namespace, b.Namespace); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Name, b.Name)
+}
+
+// appendIngressRef adds ref to refs unless it is already present
+func appendIngressRef(refs []ingressv1alpha1.ResourceReference,
+	ref ingressv1alpha1.ResourceReference) []ingressv1alpha1.ResourceReference {
+	if slices.Contains(refs, ref) {
+		return refs
+	}
+	return append(refs, ref)
+}
+
+// refsInNamespace returns the references to objects in the namespace
+func refsInNamespace(refs []ingressv1alpha1.ResourceReference,
+	namespace string) []ingressv1alpha1.ResourceReference {
+	var found []ingressv1alpha1.ResourceReference
+	for _, ref := range refs {
+		if ref.Namespace == namespace {
+			found = append(found, ref)
+		}
+	}
+	return found
+}
+
+// cleanupSteps deletes everything generated for an AppIngress being deleted: every recorded
+// object together with the ones currently described by the spec, which covers objects created
+// before they were recorded in status
+func (r *AppIngressReconciler) cleanupSteps(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject) []cleanupStep {
+	status := appIngress.GetStatus()
+	targets := appIngress.GetSpec().ExplicitTargetNamespaces()
+	steps := []cleanupStep{{what: "Ingress", cleanup: func() error {
+		refs := slices.Clone(status.Ingresses)
+		for _, namespace := range targets {
+			refs = appendIngressRef(refs, ingressRef(appIngress, namespace))
+		}
+		_, err := r.cleanupIngresses(ctx, appIngress, refs, nil)
+		return err
+	}}}
+	// Without the Gateway API there are no HTTPRoutes to delete, and no API to delete them with
+	if r.GatewayAPI {
+		steps = append(steps, cleanupStep{what: "HTTPRoute", cleanup: func() error {
+			refs := slices.Clone(status.HTTPRoutes)
+			if outputKind(appIngress) == ingressv1alpha1.OutputKindHTTPRoute {
+				for _, namespace := range targets {
+					refs = appendIngressRef(refs, ingressRef(appIngress, namespace))
+				}
+			}
+			_, err := r.cleanupCopies(ctx, appIngress, "HTTPRoute", newHTTPRoute, refs, nil)
+			return err
+		}})
+	}
+	return append(steps, cleanupStep{what: "TLS Secret", cleanup: func() error {
+		// Secret names may be templated, copies of templates that no longer render are in status
+		refs := slices.Clone(status.TLSSecrets)
+		sourceLabels, _ := r.sourceNamespaceLabels(ctx, appIngress)
+		for _, namespace := range targets {
+			rendered, err := renderAppIngress(appIngress, sourceLabels, namespace)
+			if err != nil {
+				continue
+			}
+			for _, ref := range tlsSecretRefs(rendered, namespace) {
+				refs = appendIngressRef(refs, ref)
+			}
+		}
+		_, err := r.cleanupCopies(ctx, appIngress, "Secret", newSecret, refs, nil)
+		return err
+	}}, cleanupStep{what: "backend Service", cleanup: func() error {
+		refs := slices.Clone(status.BackendServices)
+		for _, namespace := range targets {
+			for _, ref := range backendServiceRefs(appIngress, namespace) {
+				refs = appendIngressRef(refs, ref)
+			}
+		}
+		_, err := r.cleanupBackends(ctx, appIngress, refs, nil)
+		return err
+	}})
+}
+
+// cleanupIngresses deletes the referenced Ingresses except the ones in keep. It returns the
+// references that could not be deleted so they can be retried.
+func (r *AppIngressReconciler) cleanupIngresses(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	refs, keep []ingressv1alpha1.ResourceReference) ([]ingressv1alpha1.ResourceReference, error) {
+	return deleteUnkept(refs, keep, func(ref ingressv1alpha1.ResourceReference) error {
+		return r.deleteIngress(ctx, appIngress, ref)
 	})
+}
 
-	if err := r.Status().Update(ctx, appIngress); err != nil {
-		logger.Error(err, "Failed to update AppIngress status")
-		return ctrl.Result{}, err
+// managedLabels returns the template labels extended with the tracking label
+func managedLabels(appIngress ingressv1alpha1.AppIngressObject) map[string]string {
+	labels := make(map[string]string, len(appIngress.GetSpec().Template.Labels)+1)
+	for k, v := range appIngress.GetSpec().Template.Labels {
+		labels[k] = v
 	}
+	labels[ingressv1alpha1.ManagedByLabel] = ingressv1alpha1.ManagedByValue
+	return labels
+}
 
-	logger.Info("Reconciliation completed successfully")
-	return ctrl.Result{}, nil
+// managedAnnotations returns the template annotations extended with the source reference, its UID
+// and the generation the object is generated from
+func managedAnnotations(appIngress ingressv1alpha1.AppIngressObject) map[string]string {
+	annotations := make(map[string]string, len(appIngress.GetSpec().Template.Annotations)+3)
+	for k, v := range appIngress.GetSpec().Template.Annotations {
+		annotations[k] = v
+	}
+	annotations[ingressv1alpha1.SourceAnnotation] = sourceKey(appIngress)
+	annotations[ingressv1alpha1.SourceUIDAnnotation] = string(appIngress.GetUID())
+	annotations[ingressv1alpha1.SourceGenerationAnnotation] = strconv.FormatInt(appIngress.GetGeneration(), 10)
+	return annotations
+}
+
+// deleteIngress deletes the referenced Ingress if it is still managed by the AppIngress.
+// Ingresses that are gone or were taken over by someone else are left alone.
+func (r *AppIngressReconciler) deleteIngress(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	ref ingressv1alpha1.ResourceReference) error {
+	logger := log.FromContext(ctx)
+
+	ingress := &networkingv1.Ingress{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, ingress); err != nil {
+		if apierrors.IsNotFound(err) {
+			// If the Ingress is already gone, there is nothing left to clean up
+			logger.Info("Ingress already deleted or not found", "ingress", ref)
+			return nil
+		}
+		return err
+	}
+	if !ingressv1alpha1.IsManagedBy(ingress, sourceKey(appIngress)) {
+		logger.Info("Skipping deletion of Ingress not managed by this AppIngress", "ingress", ref)
+		return nil
+	}
+
+	// The UID precondition protects an Ingress recreated by someone else in the meantime
+	if err := r.Delete(ctx, ingress, client.Preconditions{UID: &ingress.UID}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	logger.Info("Deleted Ingress", "ingress", ref)
+	ingressOperations.WithLabelValues(operationDelete).Inc()
+	r.Recorder.Eventf(appIngress, corev1.EventTypeNormal, "IngressDeleted", "Deleted Ingress %s/%s",
+		ref.Namespace, ref.Name)
+	return nil
+}
+
+// findAppIngressForIngress maps a generated Ingress back to the AppIngress it was created from.
+// Owner references cannot cross namespaces, so the source is read from the tracking annotation.
+// AppIngresses that target the same name but are blocked by an ownership conflict are included
+// as well, so they can take over once the Ingress is released.
+func (r *AppIngressReconciler) findAppIngressForIngress(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	if source, ok := obj.GetAnnotations()[ingressv1alpha1.SourceAnnotation]; ok &&
+		obj.GetLabels()[ingressv1alpha1.ManagedByLabel] == ingressv1alpha1.ManagedByValue {
+		// ClusterAppIngresses are referenced by name alone
+		namespace, name, err := cache.SplitMetaNamespaceKey(source)
+		if err != nil || name == "" {
+			log.FromContext(ctx).Info("Ignoring Ingress with malformed source annotation",
+				"ingress", client.ObjectKeyFromObject(obj), "source", source)
+		} else {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: namespace, Name: name},
+			})
+		}
+	}
+
+	appIngresses, err := r.listAppIngresses(ctx, client.MatchingFields{templateNameIndexKey: obj.GetName()})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list AppIngresses for Ingress", "ingress", client.ObjectKeyFromObject(obj))
+		return requests
+	}
+	for _, appIngress := range appIngresses {
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(appIngress)}
+		if targetsNamespace(appIngress, obj.GetNamespace()) && !slices.Contains(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+
+	// Unmanaged Ingresses may hold host claims that block AppIngresses
+	if ingress, ok := obj.(*networkingv1.Ingress); ok && r.IncludeUnmanagedHosts && !ingressv1alpha1.IsManaged(ingress) {
+		for _, request := range r.findAppIngressesClaiming(ctx, claimedHosts(&ingress.Spec)) {
+			if !slices.Contains(requests, request) {
+				requests = append(requests, request)
+			}
+		}
+	}
+	return requests
+}
+
+// findAppIngressesSharingHosts maps an AppIngress or ClusterAppIngress to the other ones routing one
+// of its hosts, so the next oldest claim takes over once it is deleted or stops routing the host
+func (r *AppIngressReconciler) findAppIngressesSharingHosts(ctx context.Context, obj client.Object) []reconcile.Request {
+	appIngress := obj.(ingressv1alpha1.AppIngressObject)
+	hosts, err := r.renderedIndexValues(ctx, appIngress, appIngressIndexes[hostIndexKey])
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to render hosts of AppIngress", "appIngress", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+	return slices.DeleteFunc(r.findAppIngressesClaiming(ctx, hosts), func(request reconcile.Request) bool {
+		return request.NamespacedName == client.ObjectKeyFromObject(appIngress)
+	})
+}
+
+// findAppIngressesClaiming returns the AppIngresses routing any of the hosts
+func (r *AppIngressReconciler) findAppIngressesClaiming(ctx context.Context, hosts []string) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, host := range hosts {
+		appIngresses, err := r.listAppIngressesIndexed(ctx, hostIndexKey, host)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list AppIngresses for host", "host", host)
+			continue
+		}
+		for _, appIngress := range appIngresses {
+			request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(appIngress)}
+			if !slices.Contains(requests, request) {
+				requests = append(requests, request)
+			}
+		}
+	}
+	return requests
+}
+
+// claimedHosts returns the hosts of the host claims of the Ingress spec, without duplicates
+func claimedHosts(spec *networkingv1.IngressSpec) []string {
+	var hosts []string
+	for _, claim := range hostclaim.Claims(spec) {
+		if !slices.Contains(hosts, claim.Host) {
+			hosts = append(hosts, claim.Host)
+		}
+	}
+	return hosts
+}
+
+// targetsNamespace reports whether the namespace is listed in the AppIngress spec or status
+func targetsNamespace(appIngress ingressv1alpha1.AppIngressObject, namespace string) bool {
+	if slices.Contains(appIngress.GetSpec().ExplicitTargetNamespaces(), namespace) {
+		return true
+	}
+	return slices.ContainsFunc(appIngress.GetStatus().Targets, func(target ingressv1alpha1.TargetStatus) bool {
+		return target.Namespace == namespace
+	})
+}
+
+// findAppIngressesForNamespace maps a Namespace to the AppIngresses targeting it, so that
+// AppIngresses waiting for their namespace are reconciled as soon as it is created or deleted.
+// AppIngresses with a namespace selector are matched against the namespace labels. Both the old
+// and the new object of an update are mapped, so namespaces that stop matching are cleaned up.
+// AppIngresses inside the namespace are mapped too, since its labels are available to their template.
+func (r *AppIngressReconciler) findAppIngressesForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	logger := log.FromContext(ctx)
+
+	appIngresses, err := r.listAppIngresses(ctx, client.MatchingFields{targetNamespaceIndexKey: obj.GetName()})
+	if err != nil {
+		logger.Error(err, "Failed to list AppIngresses for namespace", "namespace", obj.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(appIngresses))
+	for _, appIngress := range appIngresses {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(appIngress)})
+	}
+
+	if appIngresses, err = r.listAppIngresses(ctx); err != nil {
+		logger.Error(err, "Failed to list AppIngresses for namespace", "namespace", obj.GetName())
+		return requests
+	}
+	for _, appIngress := range appIngresses {
+		if appIngress.GetNamespace() == obj.GetName() {
+			request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(appIngress)}
+			if !slices.Contains(requests, request) {
+				requests = append(requests, request)
+			}
+			continue
+		}
+		if appIngress.GetSpec().NamespaceSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(appIngress.GetSpec().NamespaceSelector)
+		if err != nil || !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(appIngress)}
+		if !slices.Contains(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// findAllAppIngresses maps an object to every AppIngress, e.g. when an AppIngressPolicy changes
+// and any of them may be affected
+func (r *AppIngressReconciler) findAllAppIngresses(ctx context.Context, obj client.Object) []reconcile.Request {
+	appIngresses := &ingressv1alpha1.AppIngressList{}
+	if err := r.List(ctx, appIngresses); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list AppIngresses", "object", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(appIngresses.Items))
+	for _, appIngress := range appIngresses.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&appIngress)})
+	}
+	return requests
+}
+
+// appIngressIndexes returns the values of every field index of AppIngresses and ClusterAppIngresses.
+// Both kinds are indexed the same way, so the map functions find either of them.
+var appIngressIndexes = map[string]func(ingressv1alpha1.AppIngressObject) []string{
+	targetNamespaceIndexKey: func(appIngress ingressv1alpha1.AppIngressObject) []string {
+		return appIngress.GetSpec().ExplicitTargetNamespaces()
+	},
+	templateNameIndexKey: func(appIngress ingressv1alpha1.AppIngressObject) []string {
+		return []string{appIngress.GetSpec().Template.Name}
+	},
+	hostIndexKey:           hostclaim.Hosts,
+	tlsSecretIndexKey:      tlsSecretSources,
+	backendServiceIndexKey: backendServiceSources,
+	backendIndexKey:        backendServiceNames,
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *AppIngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := hostclaim.IndexFields(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	r.indexedHosts = true
+	for key, index := range appIngressIndexes {
+		if key == hostIndexKey {
+			continue
+		}
+		for _, obj := range []client.Object{&ingressv1alpha1.AppIngress{}, &ingressv1alpha1.ClusterAppIngress{}} {
+			if err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, key,
+				func(obj client.Object) []string {
+					return indexValues(obj.(ingressv1alpha1.AppIngressObject), index)
+				}); err != nil {
+				return err
+			}
+		}
+	}
+	state.setReader(mgr.GetClient())
+
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&ingressv1alpha1.AppIngress{}).
-		Named("appingress").
-		Complete(r)
+		Watches(&ingressv1alpha1.ClusterAppIngress{}, &handler.EnqueueRequestForObject{}).
+		// Generated Ingresses live in other namespaces, so they are tracked through
+		// labels and annotations instead of Owns() to revert drift and recreate them.
+		Watches(&networkingv1.Ingress{}, handler.EnqueueRequestsFromMapFunc(r.findAppIngressForIngress)).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.findAppIngressesForNamespace)).
+		Watches(&ingressv1alpha1.AppIngress{}, handler.EnqueueRequestsFromMapFunc(r.findAppIngressesSharingHosts)).
+		Watches(&ingressv1alpha1.ClusterAppIngress{}, handler.EnqueueRequestsFromMapFunc(r.findAppIngressesSharingHosts)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findAppIngressesForCopy(tlsSecretIndexKey))).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(r.findAppIngressesForService)).
+		Watches(&discoveryv1.EndpointSlice{}, handler.EnqueueRequestsFromMapFunc(r.findAppIngressesForEndpointSlice))
+	if r.GatewayAPI {
+		builder = builder.Watches(&gatewayv1.HTTPRoute{}, handler.EnqueueRequestsFromMapFunc(r.findAppIngressForIngress))
+	}
+	if r.Policy != nil {
+		builder = builder.Watches(&ingressv1alpha1.AppIngressPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.findAllAppIngresses))
+	}
+	return builder.Named("appingress").Complete(r)
 }
@@ -18,17 +18,28 @@ package controller
 
 import (
 	"context"
+	"strconv"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/policy"
+	"github.com/rafal-jan/ingress-duplicator/internal/render"
 )
 
 var _ = Describe("AppIngress Controller", Ordered, func() {
@@ -77,8 +88,9 @@ var _ = Describe("AppIngress Controller", Ordered, func() {
 
 			// Create controller instance
 			controllerReconciler = &AppIngressReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
 			}
 		})
 
@@ -109,12 +121,1218 @@ var _ = Describe("AppIngress Controller", Ordered, func() {
 			Expect(nsCondition).NotTo(BeNil())
 			Expect(nsCondition.Status).To(Equal(metav1.ConditionFalse))
 			Expect(nsCondition.Reason).To(Equal("NotFound"))
+
+			readyCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeReady)
+			Expect(readyCondition).NotTo(BeNil())
+			Expect(readyCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(readyCondition.Reason).To(Equal("NotFound"))
+		})
+	})
+
+	Context("When target namespace is created after the AppIngress", func() {
+		const lateNs = "late-target-namespace"
+
+		BeforeEach(func() {
+			// Create AppIngress instance
+			appIngress = &ingressv1alpha1.AppIngress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: namespace,
+				},
+				Spec: ingressv1alpha1.AppIngressSpec{
+					Template: ingressv1alpha1.IngressTemplate{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test-ingress",
+						},
+						Spec: networkingv1.IngressSpec{
+							Rules: []networkingv1.IngressRule{
+								{
+									Host: "example.com",
+								},
+							},
+						},
+					},
+					TargetNamespace: lateNs,
+				},
+			}
+			Expect(k8sClient.Create(ctx, appIngress)).To(Succeed())
+
+			// Create controller instance
+			controllerReconciler = &AppIngressReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+		})
+
+		AfterEach(func() {
+			if appIngress != nil {
+				// Delete the AppIngress
+				_ = k8sClient.Delete(ctx, appIngress)
+				// Reconcile to handle the finalizer
+				_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				appIngress = nil
+			}
+		})
+
+		It("should follow the namespace lifecycle", func() {
+			// Namespace is missing at first
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			nsCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeNamespaceValid)
+			Expect(nsCondition).NotTo(BeNil())
+			Expect(nsCondition.Status).To(Equal(metav1.ConditionFalse))
+
+			// Create the namespace, the Namespace watch triggers reconciliation
+			lateNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: lateNs}}
+			Expect(k8sClient.Create(ctx, lateNamespace)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			nsCondition = findCondition(updatedAppIngress.Status.Conditions, ConditionTypeNamespaceValid)
+			Expect(nsCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      appIngress.Spec.Template.Name,
+				Namespace: lateNs,
+			}, &networkingv1.Ingress{})).To(Succeed())
+
+			// Delete the namespace. EnvTest has no namespace controller, so it stays terminating.
+			Expect(k8sClient.Delete(ctx, lateNamespace)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			nsCondition = findCondition(updatedAppIngress.Status.Conditions, ConditionTypeNamespaceValid)
+			Expect(nsCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(nsCondition.Reason).To(Equal("NotFound"))
+		})
+	})
+
+	Context("When target namespace exists", func() {
+		BeforeEach(func() {
+			// Create AppIngress instance
+			appIngress = &ingressv1alpha1.AppIngress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: namespace,
+				},
+				Spec: ingressv1alpha1.AppIngressSpec{
+					Template: ingressv1alpha1.IngressTemplate{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test-ingress",
+						},
+						Spec: networkingv1.IngressSpec{
+							Rules: []networkingv1.IngressRule{
+								{
+									Host: "example.com",
+									IngressRuleValue: networkingv1.IngressRuleValue{
+										HTTP: &networkingv1.HTTPIngressRuleValue{
+											Paths: []networkingv1.HTTPIngressPath{
+												{
+													Path:     "/",
+													PathType: &[]networkingv1.PathType{networkingv1.PathTypePrefix}[0],
+													Backend: networkingv1.IngressBackend{
+														Service: &networkingv1.IngressServiceBackend{
+															Name: "test-service",
+															Port: networkingv1.ServiceBackendPort{
+																Number: 80,
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					TargetNamespace: targetNs,
+				},
+			}
+			Expect(k8sClient.Create(ctx, appIngress)).To(Succeed())
+
+			// Create controller instance
+			controllerReconciler = &AppIngressReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+		})
+
+		AfterEach(func() {
+			if appIngress != nil {
+				// Delete the AppIngress
+				_ = k8sClient.Delete(ctx, appIngress)
+				// Reconcile to handle the finalizer
+				_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				appIngress = nil
+			}
+		})
+
+		It("should create ingress in target namespace", func() {
+			createBackendService(targetNs)
+
+			// Trigger reconciliation
+			result, err := controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+
+			// Verify ingress creation
+			createdIngress := &networkingv1.Ingress{}
+			err = k8sClient.Get(ctx, types.NamespacedName{
+				Name:      appIngress.Spec.Template.Name,
+				Namespace: targetNs,
+			}, createdIngress)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createdIngress.Spec).To(Equal(appIngress.Spec.Template.Spec))
+
+			// Verify conditions
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			Expect(updatedAppIngress.Status.Conditions).To(HaveLen(10))
+
+			nsCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeNamespaceValid)
+			Expect(nsCondition).NotTo(BeNil())
+			Expect(nsCondition.Status).To(Equal(metav1.ConditionTrue))
+
+			ingressCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeIngressCreated)
+			Expect(ingressCondition).NotTo(BeNil())
+			Expect(ingressCondition.Status).To(Equal(metav1.ConditionTrue))
+
+			conflictCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeIngressConflict)
+			Expect(conflictCondition).NotTo(BeNil())
+			Expect(conflictCondition.Status).To(Equal(metav1.ConditionFalse))
+
+			readyCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeReady)
+			Expect(readyCondition).NotTo(BeNil())
+			Expect(readyCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(readyCondition.Reason).To(Equal("AddressPending"))
+
+			Expect(updatedAppIngress.Status.ObservedGeneration).To(Equal(updatedAppIngress.Generation))
+			for _, condition := range updatedAppIngress.Status.Conditions {
+				Expect(condition.ObservedGeneration).To(Equal(updatedAppIngress.Generation), condition.Type)
+			}
+		})
+
+		It("should emit events for the ingress lifecycle", func() {
+			createBackendService(targetNs)
+			recorder := controllerReconciler.Recorder.(*record.FakeRecorder)
+
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(Equal("Normal IngressCreated Created Ingress " +
+				targetNs + "/" + appIngress.Spec.Template.Name)))
+			Expect(recorder.Events).To(Receive(Equal("Normal IngressCreated Created from AppIngress " +
+				namespace + "/" + resourceName)))
+
+			// Nothing changed, nothing is reported
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
+		})
+
+		It("should count ingress operations", func() {
+			created := testutil.ToFloat64(ingressOperations.WithLabelValues(operationCreate))
+			updated := testutil.ToFloat64(ingressOperations.WithLabelValues(operationUpdate))
+
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(ingressOperations.WithLabelValues(operationCreate))).To(Equal(created + 1))
+
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			updatedAppIngress.Spec.Template.Spec.Rules[0].Host = "updated-example.com"
+			Expect(k8sClient.Update(ctx, updatedAppIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(ingressOperations.WithLabelValues(operationUpdate))).To(Equal(updated + 1))
+		})
+
+		It("should report managed ingresses once the collector has a reader", func() {
+			collector := &stateCollector{}
+			Expect(testutil.CollectAndCount(collector)).To(BeZero())
+
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			collector.setReader(k8sClient)
+			Expect(testutil.CollectAndCount(collector, "ingress_duplicator_managed_ingresses")).To(BeNumerically(">=", 1))
+		})
+
+		It("should mirror the load-balancer status of the generated ingress", func() {
+			createBackendService(targetNs)
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("assigning an address to the ingress as an ingress controller would")
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      appIngress.Spec.Template.Name,
+				Namespace: targetNs,
+			}, ingress)).To(Succeed())
+			ingress.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{{IP: "192.0.2.10"}}
+			Expect(k8sClient.Status().Update(ctx, ingress)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			Expect(updatedAppIngress.Status.LoadBalancer.Ingress).To(Equal(ingress.Status.LoadBalancer.Ingress))
+
+			readyCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeReady)
+			Expect(readyCondition).NotTo(BeNil())
+			Expect(readyCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(readyCondition.Reason).To(Equal("AddressAssigned"))
+		})
+
+		It("should update existing ingress", func() {
+			// First reconciliation to create ingress
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Update AppIngress template
+			updatedHost := "updated-example.com"
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			updatedAppIngress.Spec.Template.Spec.Rules[0].Host = updatedHost
+			Expect(k8sClient.Update(ctx, updatedAppIngress)).To(Succeed())
+
+			// Second reconciliation to update ingress
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Verify ingress update
+			updatedIngress := &networkingv1.Ingress{}
+			err = k8sClient.Get(ctx, types.NamespacedName{
+				Name:      appIngress.Spec.Template.Name,
+				Namespace: targetNs,
+			}, updatedIngress)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updatedIngress.Spec.Rules[0].Host).To(Equal(updatedHost))
+
+			// Status and the Ingress reflect the new generation
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			Expect(updatedAppIngress.Status.ObservedGeneration).To(Equal(updatedAppIngress.Generation))
+			Expect(updatedIngress.Annotations).To(HaveKeyWithValue(ingressv1alpha1.SourceUIDAnnotation,
+				string(updatedAppIngress.UID)))
+			Expect(updatedIngress.Annotations).To(HaveKeyWithValue(ingressv1alpha1.SourceGenerationAnnotation,
+				strconv.FormatInt(updatedAppIngress.Generation, 10)))
+		})
+
+		It("should revert manual changes to the generated ingress", func() {
+			// First reconciliation to create ingress
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			ingressKey := types.NamespacedName{Name: appIngress.Spec.Template.Name, Namespace: targetNs}
+			createdIngress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, ingressKey, createdIngress)).To(Succeed())
+			Expect(createdIngress.Labels).To(HaveKeyWithValue(ingressv1alpha1.ManagedByLabel, ingressv1alpha1.ManagedByValue))
+			Expect(createdIngress.Annotations).To(HaveKeyWithValue(ingressv1alpha1.SourceAnnotation, namespace+"/"+resourceName))
+
+			// The watch maps the Ingress back to its source AppIngress
+			requests := controllerReconciler.findAppIngressForIngress(ctx, createdIngress)
+			Expect(requests).To(ConsistOf(ctrl.Request{NamespacedName: namespacedName}))
+
+			// Edit the Ingress by hand
+			createdIngress.Spec.Rules[0].Host = "drifted.example.com"
+			Expect(k8sClient.Update(ctx, createdIngress)).To(Succeed())
+
+			// Reconciliation triggered by the Ingress watch
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			revertedIngress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, ingressKey, revertedIngress)).To(Succeed())
+			Expect(revertedIngress.Spec.Rules[0].Host).To(Equal("example.com"))
+		})
+
+		It("should preserve fields set by other controllers", func() {
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Annotate the Ingress as cert-manager or external-dns would
+			ingressKey := types.NamespacedName{Name: appIngress.Spec.Template.Name, Namespace: targetNs}
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, ingressKey, ingress)).To(Succeed())
+			ingress.Annotations["external-dns.alpha.kubernetes.io/hostname"] = "example.com"
+			Expect(k8sClient.Update(ctx, ingress)).To(Succeed())
+
+			// Change the template so the Ingress is applied again
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			updatedAppIngress.Spec.Template.Annotations = map[string]string{"team": "platform"}
+			Expect(k8sClient.Update(ctx, updatedAppIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, ingressKey, ingress)).To(Succeed())
+			Expect(ingress.Annotations).To(HaveKeyWithValue("external-dns.alpha.kubernetes.io/hostname", "example.com"))
+			Expect(ingress.Annotations).To(HaveKeyWithValue("team", "platform"))
+		})
+
+		It("should report field conflicts instead of forcing them when asked to", func() {
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			updatedAppIngress.Spec.FieldConflictPolicy = ingressv1alpha1.FieldConflictPolicyAbort
+			Expect(k8sClient.Update(ctx, updatedAppIngress)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Edit a field owned by the controller by hand
+			ingressKey := types.NamespacedName{Name: appIngress.Spec.Template.Name, Namespace: targetNs}
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, ingressKey, ingress)).To(Succeed())
+			ingress.Spec.Rules[0].Host = "drifted.example.com"
+			Expect(k8sClient.Update(ctx, ingress)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, ingressKey, ingress)).To(Succeed())
+			Expect(ingress.Spec.Rules[0].Host).To(Equal("drifted.example.com"))
+
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			conflictCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeIngressConflict)
+			Expect(conflictCondition).NotTo(BeNil())
+			Expect(conflictCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(conflictCondition.Reason).To(Equal("FieldConflict"))
+		})
+
+		It("should recreate a manually deleted ingress", func() {
+			// First reconciliation to create ingress
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			ingressKey := types.NamespacedName{Name: appIngress.Spec.Template.Name, Namespace: targetNs}
+			Expect(k8sClient.Delete(ctx, &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: ingressKey.Name, Namespace: ingressKey.Namespace},
+			})).To(Succeed())
+
+			// Reconciliation triggered by the Ingress watch
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, ingressKey, &networkingv1.Ingress{})).To(Succeed())
+		})
+
+		It("should delete the old ingress when the template name changes", func() {
+			// First reconciliation to create ingress
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Rename the Ingress in the template
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			updatedAppIngress.Spec.Template.Name = "renamed-ingress"
+			Expect(k8sClient.Update(ctx, updatedAppIngress)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Old Ingress is gone, new one exists and is the only one recorded
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "test-ingress", Namespace: targetNs}, &networkingv1.Ingress{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "renamed-ingress", Namespace: targetNs},
+				&networkingv1.Ingress{})).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			Expect(updatedAppIngress.Status.Ingresses).To(ConsistOf(ingressv1alpha1.ResourceReference{
+				Namespace: targetNs,
+				Name:      "renamed-ingress",
+			}))
+		})
+
+		It("should delete the old ingress when the target namespace changes", func() {
+			// First reconciliation to create ingress
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Move the Ingress to another namespace
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			updatedAppIngress.Spec.TargetNamespace = namespace
+			Expect(k8sClient.Update(ctx, updatedAppIngress)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "test-ingress", Namespace: targetNs}, &networkingv1.Ingress{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-ingress", Namespace: namespace},
+				&networkingv1.Ingress{})).To(Succeed())
+
+			// Cleanup on deletion removes the Ingress from the new namespace
+			Expect(k8sClient.Delete(ctx, updatedAppIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "test-ingress", Namespace: namespace}, &networkingv1.Ingress{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			appIngress = nil
+		})
+
+		It("should copy shared TLS secrets into the target namespace", func() {
+			const sourceNs = "tls-source-namespace"
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: sourceNs}})).To(Succeed())
+			source := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "wildcard-tls", Namespace: sourceNs},
+				Type:       corev1.SecretTypeTLS,
+				Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+			}
+			Expect(k8sClient.Create(ctx, source)).To(Succeed())
+			copyKey := types.NamespacedName{Name: source.Name, Namespace: targetNs}
+
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			appIngress.Spec.Template.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: source.Name}}
+			appIngress.Spec.TLSSecretSource = &ingressv1alpha1.TLSSecretSource{Namespace: sourceNs}
+			Expect(k8sClient.Update(ctx, appIngress)).To(Succeed())
+
+			By("refusing to copy a secret that is not shared")
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, copyKey, &corev1.Secret{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			secretsCondition := findCondition(appIngress.Status.Conditions, ConditionTypeTLSSecretsSynced)
+			Expect(secretsCondition).NotTo(BeNil())
+			Expect(secretsCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(secretsCondition.Reason).To(Equal("NotShared"))
+
+			By("copying the secret once it is shared")
+			source.Annotations = map[string]string{ingressv1alpha1.ShareWithAnnotation: namespace}
+			Expect(k8sClient.Update(ctx, source)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			secretCopy := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, copyKey, secretCopy)).To(Succeed())
+			Expect(secretCopy.Type).To(Equal(corev1.SecretTypeTLS))
+			Expect(secretCopy.Data).To(Equal(source.Data))
+			Expect(ingressv1alpha1.IsManagedBy(secretCopy, namespace+"/"+resourceName)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			Expect(appIngress.Status.TLSSecrets).To(ConsistOf(ingressv1alpha1.ResourceReference{
+				Namespace: targetNs, Name: source.Name,
+			}))
+			secretsCondition = findCondition(appIngress.Status.Conditions, ConditionTypeTLSSecretsSynced)
+			Expect(secretsCondition.Status).To(Equal(metav1.ConditionTrue))
+
+			By("propagating a rotated certificate")
+			source.Data[corev1.TLSCertKey] = []byte("rotated")
+			Expect(k8sClient.Update(ctx, source)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, copyKey, secretCopy)).To(Succeed())
+			Expect(secretCopy.Data[corev1.TLSCertKey]).To(Equal([]byte("rotated")))
+
+			By("deleting the copy together with the AppIngress")
+			Expect(k8sClient.Delete(ctx, appIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, copyKey, &corev1.Secret{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(source), &corev1.Secret{})).To(Succeed())
+			appIngress = nil
+		})
+
+		It("should bridge backend services into the target namespace", func() {
+			serviceKey := types.NamespacedName{Name: "test-service", Namespace: targetNs}
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			appIngress.Spec.BackendNamespace = namespace
+			Expect(k8sClient.Update(ctx, appIngress)).To(Succeed())
+
+			By("reporting a missing backend service")
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			backendsCondition := findCondition(appIngress.Status.Conditions, ConditionTypeBackendsSynced)
+			Expect(backendsCondition).NotTo(BeNil())
+			Expect(backendsCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(backendsCondition.Reason).To(Equal("SourceNotFound"))
+
+			By("creating an ExternalName service once the backend exists")
+			backend := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: namespace},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, backend)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, backend)).To(Succeed())
+			})
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			bridged := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, serviceKey, bridged)).To(Succeed())
+			Expect(bridged.Spec.Type).To(Equal(corev1.ServiceTypeExternalName))
+			Expect(bridged.Spec.ExternalName).To(Equal("test-service." + namespace + ".svc.cluster.local"))
+			Expect(bridged.Spec.Ports).To(HaveLen(1))
+			Expect(bridged.Spec.Ports[0].Name).To(Equal("http"))
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			backendsCondition = findCondition(appIngress.Status.Conditions, ConditionTypeBackendsSynced)
+			Expect(backendsCondition.Status).To(Equal(metav1.ConditionTrue))
+
+			By("deleting the bridged service together with the AppIngress")
+			Expect(k8sClient.Delete(ctx, appIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, serviceKey, &corev1.Service{}))).To(BeTrue())
+			appIngress = nil
+		})
+
+		It("should mirror the endpoint slices of backend services when asked to", func() {
+			serviceKey := types.NamespacedName{Name: "test-service", Namespace: targetNs}
+			backend := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: namespace},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080)}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, backend)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, backend)).To(Succeed())
+			})
+			// EnvTest runs no EndpointSlice controller, so the slice of the backend is created by hand
+			ready := true
+			port := int32(8080)
+			portName := "http"
+			sourceSlice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-service-abcde",
+					Namespace: namespace,
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "test-service"},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{{
+					Addresses:  []string{"10.0.0.1"},
+					Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+				}},
+				Ports: []discoveryv1.EndpointPort{{Name: &portName, Port: &port}},
+			}
+			Expect(k8sClient.Create(ctx, sourceSlice)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, sourceSlice)).To(Succeed())
+			})
+
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			appIngress.Spec.BackendNamespace = namespace
+			appIngress.Spec.BackendBridgeMode = ingressv1alpha1.BackendBridgeModeEndpointSlice
+			Expect(k8sClient.Update(ctx, appIngress)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			bridged := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, serviceKey, bridged)).To(Succeed())
+			Expect(bridged.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			Expect(bridged.Spec.Selector).To(BeEmpty())
+			Expect(bridged.Spec.Ports[0].TargetPort).To(Equal(intstr.FromInt32(8080)))
+
+			mirrored := &discoveryv1.EndpointSlice{}
+			mirroredKey := types.NamespacedName{Name: sourceSlice.Name, Namespace: targetNs}
+			Expect(k8sClient.Get(ctx, mirroredKey, mirrored)).To(Succeed())
+			Expect(mirrored.Labels).To(HaveKeyWithValue(discoveryv1.LabelServiceName, "test-service"))
+			Expect(mirrored.Endpoints).To(Equal(sourceSlice.Endpoints))
+			Expect(mirrored.Ports).To(Equal(sourceSlice.Ports))
+
+			By("mirroring readiness changes")
+			notReady := false
+			sourceSlice.Endpoints[0].Conditions.Ready = &notReady
+			Expect(k8sClient.Update(ctx, sourceSlice)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, mirroredKey, mirrored)).To(Succeed())
+			Expect(*mirrored.Endpoints[0].Conditions.Ready).To(BeFalse())
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			backendsCondition := findCondition(appIngress.Status.Conditions, ConditionTypeBackendsSynced)
+			Expect(backendsCondition.Status).To(Equal(metav1.ConditionTrue))
+
+			By("deleting the bridged service and its slices together with the AppIngress")
+			Expect(k8sClient.Delete(ctx, appIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, serviceKey, &corev1.Service{}))).To(BeTrue())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, mirroredKey, &discoveryv1.EndpointSlice{}))).To(BeTrue())
+			appIngress = nil
+		})
+
+		It("should not overwrite endpoint slices it does not mirror", func() {
+			backend := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: namespace},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080)}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, backend)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, backend)).To(Succeed())
+			})
+			sourceSlice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-service-fghij",
+					Namespace: namespace,
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "test-service"},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}},
+			}
+			Expect(k8sClient.Create(ctx, sourceSlice)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, sourceSlice)).To(Succeed())
+			})
+			// A slice of another Service in the target namespace that happens to have the same name
+			foreignSlice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      sourceSlice.Name,
+					Namespace: targetNs,
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "other-service"},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.2"}}},
+			}
+			Expect(k8sClient.Create(ctx, foreignSlice)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, foreignSlice)).To(Succeed())
+			})
+
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			appIngress.Spec.BackendNamespace = namespace
+			appIngress.Spec.BackendBridgeMode = ingressv1alpha1.BackendBridgeModeEndpointSlice
+			Expect(k8sClient.Update(ctx, appIngress)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			existing := &discoveryv1.EndpointSlice{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreignSlice), existing)).To(Succeed())
+			Expect(existing.Labels).To(HaveKeyWithValue(discoveryv1.LabelServiceName, "other-service"))
+			Expect(existing.Labels).NotTo(HaveKey(ingressv1alpha1.ManagedByLabel))
+			Expect(existing.Endpoints[0].Addresses).To(ConsistOf("10.0.0.2"))
+
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			backendsCondition := findCondition(appIngress.Status.Conditions, ConditionTypeBackendsSynced)
+			Expect(backendsCondition).NotTo(BeNil())
+			Expect(backendsCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(backendsCondition.Reason).To(Equal("Conflict"))
+			Expect(backendsCondition.Message).To(ContainSubstring("EndpointSlice " + targetNs + "/" + sourceSlice.Name))
+		})
+
+		It("should render the template for the target namespace", func() {
+			ingressKey := types.NamespacedName{Name: appIngress.Spec.Template.Name, Namespace: targetNs}
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			appIngress.Spec.Parameters = map[string]string{"team": "payments"}
+			appIngress.Spec.Template.Annotations = map[string]string{
+				"example.com/owner": "{{ .Parameters.team }}/{{ .AppIngress.Name }}",
+			}
+			appIngress.Spec.Template.Spec.Rules[0].Host = "{{ .TargetNamespace }}.example.com"
+			appIngress.Spec.Template.Spec.Rules[0].HTTP.Paths[0].Path = "/{{ .Parameters.team }}"
+			Expect(k8sClient.Update(ctx, appIngress)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, ingressKey, ingress)).To(Succeed())
+			Expect(ingress.Spec.Rules[0].Host).To(Equal(targetNs + ".example.com"))
+			Expect(ingress.Spec.Rules[0].HTTP.Paths[0].Path).To(Equal("/payments"))
+			Expect(ingress.Annotations).To(HaveKeyWithValue("example.com/owner", "payments/"+resourceName))
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			renderedCondition := findCondition(appIngress.Status.Conditions, ConditionTypeTemplateRendered)
+			Expect(renderedCondition).NotTo(BeNil())
+			Expect(renderedCondition.Status).To(Equal(metav1.ConditionTrue))
+
+			By("keeping the ingress while the template does not render")
+			appIngress.Spec.Template.Spec.Rules[0].Host = "{{ .Parameters.missing }}.example.com"
+			Expect(k8sClient.Update(ctx, appIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, ingressKey, ingress)).To(Succeed())
+			Expect(ingress.Spec.Rules[0].Host).To(Equal(targetNs + ".example.com"))
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			renderedCondition = findCondition(appIngress.Status.Conditions, ConditionTypeTemplateRendered)
+			Expect(renderedCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(renderedCondition.Reason).To(Equal("RenderFailed"))
+			Expect(renderedCondition.Message).To(ContainSubstring("spec.template.spec.rules[0].host"))
+			readyCondition := findCondition(appIngress.Status.Conditions, ConditionTypeReady)
+			Expect(readyCondition.Reason).To(Equal("RenderFailed"))
+		})
+
+		It("should look up the names rendered for every target namespace", func() {
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			appIngress.Spec.TLSSecretSource = &ingressv1alpha1.TLSSecretSource{Namespace: namespace}
+			appIngress.Spec.Template.Spec.Rules[0].Host = "{{ .TargetNamespace }}.example.com"
+			appIngress.Spec.Template.Spec.TLS = []networkingv1.IngressTLS{{
+				Hosts:      []string{"{{ .TargetNamespace }}.example.com"},
+				SecretName: "{{ .TargetNamespace }}-tls",
+			}, {
+				Hosts:      []string{"shared.example.com"},
+				SecretName: "shared-tls",
+			}}
+
+			By("indexing templated names under a marker")
+			Expect(indexValues(appIngress, appIngressIndexes[tlsSecretIndexKey])).To(ConsistOf(
+				namespace+"/shared-tls", render.TemplatedIndexValue))
+			Expect(indexValues(appIngress, appIngressIndexes[hostIndexKey])).To(ConsistOf(render.TemplatedIndexValue))
+
+			By("rendering them when they are looked up")
+			Expect(controllerReconciler.renderedIndexValues(ctx, appIngress, appIngressIndexes[tlsSecretIndexKey])).
+				To(ConsistOf(namespace+"/shared-tls", namespace+"/"+targetNs+"-tls"))
+			Expect(controllerReconciler.renderedIndexValues(ctx, appIngress, appIngressIndexes[hostIndexKey])).
+				To(ConsistOf(targetNs + ".example.com"))
+		})
+
+		It("should replace the ingress with an HTTPRoute when asked to", func() {
+			controllerReconciler.GatewayAPI = true
+			key := types.NamespacedName{Name: appIngress.Spec.Template.Name, Namespace: targetNs}
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, &networkingv1.Ingress{})).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			appIngress.Spec.OutputKind = ingressv1alpha1.OutputKindHTTPRoute
+			appIngress.Spec.HTTPRoute = &ingressv1alpha1.HTTPRouteOutput{
+				ParentRefs: []gatewayv1.ParentReference{{Name: "shared-gateway"}},
+			}
+			Expect(k8sClient.Update(ctx, appIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			route := &gatewayv1.HTTPRoute{}
+			Expect(k8sClient.Get(ctx, key, route)).To(Succeed())
+			Expect(route.Spec.ParentRefs).To(HaveLen(1))
+			Expect(route.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("example.com")))
+			Expect(route.Spec.Rules).To(HaveLen(1))
+			Expect(*route.Spec.Rules[0].Matches[0].Path.Value).To(Equal("/"))
+			Expect(route.Spec.Rules[0].BackendRefs[0].Name).To(Equal(gatewayv1.ObjectName("test-service")))
+			Expect(route.Annotations).To(HaveKeyWithValue(ingressv1alpha1.SourceAnnotation, namespace+"/"+resourceName))
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, key, &networkingv1.Ingress{}))).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			Expect(appIngress.Status.Ingresses).To(BeEmpty())
+			Expect(appIngress.Status.HTTPRoutes).To(ConsistOf(ingressv1alpha1.ResourceReference{
+				Namespace: targetNs, Name: appIngress.Spec.Template.Name,
+			}))
+
+			By("deleting the HTTPRoute together with the AppIngress")
+			Expect(k8sClient.Delete(ctx, appIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, key, &gatewayv1.HTTPRoute{}))).To(BeTrue())
+			appIngress = nil
+		})
+
+		It("should report backend services missing from the target namespace", func() {
+			recorder := controllerReconciler.Recorder.(*record.FakeRecorder)
+
+			By("writing the ingress even though its backend does not exist")
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      appIngress.Spec.Template.Name,
+				Namespace: targetNs,
+			}, &networkingv1.Ingress{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			resolvedCondition := findCondition(appIngress.Status.Conditions, ConditionTypeBackendsResolved)
+			Expect(resolvedCondition).NotTo(BeNil())
+			Expect(resolvedCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(resolvedCondition.Reason).To(Equal("ServiceNotFound"))
+			Expect(appIngress.Status.Backends).To(ConsistOf(HaveField("Reason", "ServiceNotFound")))
+			readyCondition := findCondition(appIngress.Status.Conditions, ConditionTypeReady)
+			Expect(readyCondition.Reason).To(Equal("ServiceNotFound"))
+			Eventually(recorder.Events).Should(Receive(HavePrefix("Warning BackendNotFound")))
+
+			By("reporting a missing port")
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: targetNs},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 8080}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, service)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, service)).To(Succeed())
+			})
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			resolvedCondition = findCondition(appIngress.Status.Conditions, ConditionTypeBackendsResolved)
+			Expect(resolvedCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(resolvedCondition.Reason).To(Equal("PortNotFound"))
+
+			By("resolving the backend once the port exists")
+			service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{Name: "web", Port: 80})
+			Expect(k8sClient.Update(ctx, service)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			resolvedCondition = findCondition(appIngress.Status.Conditions, ConditionTypeBackendsResolved)
+			Expect(resolvedCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(appIngress.Status.Backends).To(Equal([]ingressv1alpha1.BackendStatus{{
+				Namespace: targetNs,
+				Service:   "test-service",
+				Port:      "80",
+				Status:    metav1.ConditionTrue,
+				Reason:    "Resolved",
+				Message:   "Service port exists",
+			}}))
+		})
+
+		It("should only write into namespaces that consent when consent is required", func() {
+			controllerReconciler.RequireConsent = true
+			ingressKey := types.NamespacedName{Name: appIngress.Spec.Template.Name, Namespace: targetNs}
+
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, ingressKey, &networkingv1.Ingress{}))).To(BeTrue())
+
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			nsCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeNamespaceValid)
+			Expect(nsCondition).NotTo(BeNil())
+			Expect(nsCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(nsCondition.Reason).To(Equal("NotPermitted"))
+
+			By("consenting through the namespace label")
+			targetNamespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: targetNs}, targetNamespace)).To(Succeed())
+			targetNamespace.Labels[ingressv1alpha1.AcceptFromKey] = namespace
+			Expect(k8sClient.Update(ctx, targetNamespace)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: targetNs}, targetNamespace)).To(Succeed())
+				delete(targetNamespace.Labels, ingressv1alpha1.AcceptFromKey)
+				Expect(k8sClient.Update(ctx, targetNamespace)).To(Succeed())
+			})
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, ingressKey, &networkingv1.Ingress{})).To(Succeed())
+
+			By("revoking consent")
+			delete(targetNamespace.Labels, ingressv1alpha1.AcceptFromKey)
+			Expect(k8sClient.Update(ctx, targetNamespace)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, ingressKey, &networkingv1.Ingress{}))).To(BeTrue())
+		})
+
+		It("should not write the ingress of a younger AppIngress claiming the same host", func() {
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			// The name sorts after the first AppIngress, so it loses even when created in the same second
+			rival := &ingressv1alpha1.AppIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-rival", Namespace: namespace},
+				Spec:       *appIngress.Spec.DeepCopy(),
+			}
+			rival.Spec.Template.Name = "rival-ingress"
+			rivalName := client.ObjectKeyFromObject(rival)
+			Expect(k8sClient.Create(ctx, rival)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, rival)).To(Succeed())
+				_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: rivalName})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: rivalName})
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "rival-ingress", Namespace: targetNs}, &networkingv1.Ingress{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, rivalName, rival)).To(Succeed())
+			hostCondition := findCondition(rival.Status.Conditions, ConditionTypeHostConflict)
+			Expect(hostCondition).NotTo(BeNil())
+			Expect(hostCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(hostCondition.Message).To(ContainSubstring("AppIngress " + namespace + "/" + resourceName))
+			readyCondition := findCondition(rival.Status.Conditions, ConditionTypeReady)
+			Expect(readyCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(readyCondition.Reason).To(Equal("HostClaimed"))
+
+			By("taking the host over once the older AppIngress stops routing it")
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			appIngress.Spec.Template.Spec.Rules[0].Host = "moved.example.com"
+			Expect(k8sClient.Update(ctx, appIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: rivalName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "rival-ingress", Namespace: targetNs},
+				&networkingv1.Ingress{})).To(Succeed())
+		})
+
+		It("should compare templated hosts once rendered for the target namespace", func() {
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			rival := &ingressv1alpha1.AppIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-templated", Namespace: namespace},
+				Spec:       *appIngress.Spec.DeepCopy(),
+			}
+			rival.Spec.Template.Name = "templated-ingress"
+			rival.Spec.Parameters = map[string]string{"domain": "com"}
+			rival.Spec.Template.Spec.Rules[0].Host = "example.{{ .Parameters.domain }}"
+			rivalName := client.ObjectKeyFromObject(rival)
+			Expect(k8sClient.Create(ctx, rival)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, rival)).To(Succeed())
+				_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: rivalName})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: rivalName})
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "templated-ingress", Namespace: targetNs},
+				&networkingv1.Ingress{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, rivalName, rival)).To(Succeed())
+			hostCondition := findCondition(rival.Status.Conditions, ConditionTypeHostConflict)
+			Expect(hostCondition).NotTo(BeNil())
+			Expect(hostCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(hostCondition.Message).To(ContainSubstring("example.com/"))
+		})
+
+		It("should ignore ingresses it does not manage", func() {
+			unmanaged := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "unmanaged",
+					Namespace:   targetNs,
+					Annotations: map[string]string{ingressv1alpha1.SourceAnnotation: namespace + "/" + resourceName},
+				},
+			}
+			Expect(controllerReconciler.findAppIngressForIngress(ctx, unmanaged)).To(BeEmpty())
+		})
+	})
+
+	Context("When targeting multiple namespaces", func() {
+		const (
+			selectedNs = "selected-target-namespace"
+			missingNs  = "missing-target-namespace"
+		)
+
+		BeforeAll(func() {
+			// Create a namespace that is picked up through the namespace selector
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   selectedNs,
+					Labels: map[string]string{"tenant": "selected"},
+				},
+			})).To(Succeed())
+		})
+
+		BeforeEach(func() {
+			// Create AppIngress instance
+			appIngress = &ingressv1alpha1.AppIngress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: namespace,
+				},
+				Spec: ingressv1alpha1.AppIngressSpec{
+					Template: ingressv1alpha1.IngressTemplate{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test-ingress",
+						},
+						Spec: networkingv1.IngressSpec{
+							Rules: []networkingv1.IngressRule{
+								{
+									Host: "example.com",
+								},
+							},
+						},
+					},
+					TargetNamespaces: []string{targetNs, missingNs},
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"tenant": "selected"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, appIngress)).To(Succeed())
+
+			// Create controller instance
+			controllerReconciler = &AppIngressReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+		})
+
+		AfterEach(func() {
+			if appIngress != nil {
+				// Delete the AppIngress
+				_ = k8sClient.Delete(ctx, appIngress)
+				// Reconcile to handle the finalizer
+				_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				appIngress = nil
+			}
+		})
+
+		It("should create an ingress in every existing target namespace", func() {
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, ns := range []string{targetNs, selectedNs} {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-ingress", Namespace: ns},
+					&networkingv1.Ingress{})).To(Succeed())
+			}
+
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			Expect(updatedAppIngress.Status.Ingresses).To(HaveLen(2))
+			Expect(updatedAppIngress.Status.Targets).To(HaveLen(3))
+			Expect(updatedAppIngress.Status.TargetCount).To(BeEquivalentTo(3))
+
+			missingTarget := updatedAppIngress.Status.Targets[0]
+			Expect(missingTarget.Namespace).To(Equal(missingNs))
+			Expect(missingTarget.Status).To(Equal(metav1.ConditionFalse))
+			Expect(missingTarget.Reason).To(Equal("NotFound"))
+
+			nsCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeNamespaceValid)
+			Expect(nsCondition).NotTo(BeNil())
+			Expect(nsCondition.Status).To(Equal(metav1.ConditionFalse))
+
+			ingressCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeIngressCreated)
+			Expect(ingressCondition).NotTo(BeNil())
+			Expect(ingressCondition.Status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("should skip target namespaces no AppIngressPolicy allows", func() {
+			appIngressPolicy := &ingressv1alpha1.AppIngressPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "default-to-target"},
+				Spec: ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaces: []string{namespace},
+					TargetNamespaces: []string{targetNs, missingNs},
+				},
+			}
+			Expect(k8sClient.Create(ctx, appIngressPolicy)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, appIngressPolicy)).To(Succeed())
+			})
+			controllerReconciler.Policy = &policy.Evaluator{Reader: k8sClient}
+
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-ingress", Namespace: targetNs},
+				&networkingv1.Ingress{})).To(Succeed())
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "test-ingress", Namespace: selectedNs}, &networkingv1.Ingress{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			Expect(updatedAppIngress.Status.Targets).To(ContainElement(ingressv1alpha1.TargetStatus{
+				Namespace: selectedNs,
+				Status:    metav1.ConditionFalse,
+				Reason:    "PolicyDenied",
+				Message:   "no AppIngressPolicy allows namespace " + namespace + " to target namespace " + selectedNs,
+			}))
+
+			policyCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypePolicyAllowed)
+			Expect(policyCondition).NotTo(BeNil())
+			Expect(policyCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(policyCondition.Reason).To(Equal("Denied"))
+		})
+
+		It("should skip denied namespaces even when they are selected by labels", func() {
+			controllerReconciler.DeniedNamespaces = []string{selectedNs}
+
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-ingress", Namespace: targetNs},
+				&networkingv1.Ingress{})).To(Succeed())
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "test-ingress", Namespace: selectedNs}, &networkingv1.Ingress{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			Expect(updatedAppIngress.Status.Targets).To(ContainElement(ingressv1alpha1.TargetStatus{
+				Namespace: selectedNs,
+				Status:    metav1.ConditionFalse,
+				Reason:    "PolicyDenied",
+				Message:   "namespace " + selectedNs + " may not be targeted by an AppIngress",
+			}))
+			policyCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypePolicyAllowed)
+			Expect(policyCondition).NotTo(BeNil())
+			Expect(policyCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(policyCondition.Reason).To(Equal("Denied"))
+		})
+
+		It("should remove the ingress when a namespace stops matching the selector", func() {
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Drop the label selecting the namespace
+			selected := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: selectedNs}, selected)).To(Succeed())
+			delete(selected.Labels, "tenant")
+			Expect(k8sClient.Update(ctx, selected)).To(Succeed())
+
+			// Reconciliation triggered by the Namespace watch
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "test-ingress", Namespace: selectedNs}, &networkingv1.Ingress{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			Expect(updatedAppIngress.Status.Ingresses).To(ConsistOf(ingressv1alpha1.ResourceReference{
+				Namespace: targetNs,
+				Name:      "test-ingress",
+			}))
+
+			// Restore the label for other tests
+			selected.Labels["tenant"] = "selected"
+			Expect(k8sClient.Update(ctx, selected)).To(Succeed())
 		})
 	})
 
-	Context("When target namespace exists", func() {
+	Context("When an Ingress with the same name already exists", func() {
+		const foreignIngressName = "foreign-ingress"
+
+		var foreignIngress *networkingv1.Ingress
+
 		BeforeEach(func() {
-			// Create AppIngress instance
+			// Create an Ingress that was not generated by the controller
+			foreignIngress = &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      foreignIngressName,
+					Namespace: targetNs,
+					Labels:    map[string]string{"team": "foreign"},
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{
+							Host: "foreign.example.com",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, foreignIngress)).To(Succeed())
+
+			// Create AppIngress instance targeting the same name
 			appIngress = &ingressv1alpha1.AppIngress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      resourceName,
@@ -123,30 +1341,12 @@ var _ = Describe("AppIngress Controller", Ordered, func() {
 				Spec: ingressv1alpha1.AppIngressSpec{
 					Template: ingressv1alpha1.IngressTemplate{
 						ObjectMeta: metav1.ObjectMeta{
-							Name: "test-ingress",
+							Name: foreignIngressName,
 						},
 						Spec: networkingv1.IngressSpec{
 							Rules: []networkingv1.IngressRule{
 								{
 									Host: "example.com",
-									IngressRuleValue: networkingv1.IngressRuleValue{
-										HTTP: &networkingv1.HTTPIngressRuleValue{
-											Paths: []networkingv1.HTTPIngressPath{
-												{
-													Path:     "/",
-													PathType: &[]networkingv1.PathType{networkingv1.PathTypePrefix}[0],
-													Backend: networkingv1.IngressBackend{
-														Service: &networkingv1.IngressServiceBackend{
-															Name: "test-service",
-															Port: networkingv1.ServiceBackendPort{
-																Number: 80,
-															},
-														},
-													},
-												},
-											},
-										},
-									},
 								},
 							},
 						},
@@ -158,8 +1358,9 @@ var _ = Describe("AppIngress Controller", Ordered, func() {
 
 			// Create controller instance
 			controllerReconciler = &AppIngressReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
 			}
 		})
 
@@ -172,67 +1373,297 @@ var _ = Describe("AppIngress Controller", Ordered, func() {
 				Expect(err).NotTo(HaveOccurred())
 				appIngress = nil
 			}
+			_ = k8sClient.Delete(ctx, foreignIngress)
 		})
 
-		It("should create ingress in target namespace", func() {
-			// Trigger reconciliation
-			result, err := controllerReconciler.Reconcile(ctx, ctrl.Request{
-				NamespacedName: namespacedName,
-			})
+		It("should refuse to overwrite the ingress by default", func() {
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result).To(Equal(ctrl.Result{}))
 
-			// Verify ingress creation
-			createdIngress := &networkingv1.Ingress{}
-			err = k8sClient.Get(ctx, types.NamespacedName{
-				Name:      appIngress.Spec.Template.Name,
-				Namespace: targetNs,
-			}, createdIngress)
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			conflictCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeIngressConflict)
+			Expect(conflictCondition).NotTo(BeNil())
+			Expect(conflictCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(conflictCondition.Reason).To(Equal("NotManaged"))
+			Expect(updatedAppIngress.Status.Ingresses).To(BeEmpty())
+
+			// The existing Ingress is untouched
+			existingIngress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreignIngress), existingIngress)).To(Succeed())
+			Expect(existingIngress.Spec.Rules[0].Host).To(Equal("foreign.example.com"))
+			Expect(existingIngress.Labels).NotTo(HaveKey(ingressv1alpha1.ManagedByLabel))
+
+			// Deleting the AppIngress leaves the foreign Ingress in place
+			Expect(k8sClient.Delete(ctx, updatedAppIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(createdIngress.Spec).To(Equal(appIngress.Spec.Template.Spec))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreignIngress), existingIngress)).To(Succeed())
+			appIngress = nil
+		})
 
-			// Verify conditions
+		It("should adopt an unowned ingress when allowed", func() {
 			updatedAppIngress := &ingressv1alpha1.AppIngress{}
 			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
-			Expect(updatedAppIngress.Status.Conditions).To(HaveLen(2))
+			updatedAppIngress.Spec.AdoptionPolicy = ingressv1alpha1.AdoptionPolicyIfUnowned
+			Expect(k8sClient.Update(ctx, updatedAppIngress)).To(Succeed())
 
-			nsCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeNamespaceValid)
-			Expect(nsCondition).NotTo(BeNil())
-			Expect(nsCondition.Status).To(Equal(metav1.ConditionTrue))
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
 
-			ingressCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeIngressCreated)
-			Expect(ingressCondition).NotTo(BeNil())
-			Expect(ingressCondition.Status).To(Equal(metav1.ConditionTrue))
+			adoptedIngress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreignIngress), adoptedIngress)).To(Succeed())
+			Expect(adoptedIngress.Spec.Rules[0].Host).To(Equal("example.com"))
+			Expect(adoptedIngress.Annotations).To(HaveKeyWithValue(ingressv1alpha1.SourceAnnotation, namespace+"/"+resourceName))
+
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			conflictCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeIngressConflict)
+			Expect(conflictCondition).NotTo(BeNil())
+			Expect(conflictCondition.Status).To(Equal(metav1.ConditionFalse))
 		})
 
-		It("should update existing ingress", func() {
-			// First reconciliation to create ingress
-			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{
-				NamespacedName: namespacedName,
-			})
+		It("should not treat the hosts of an ingress it adopts as claimed", func() {
+			controllerReconciler.IncludeUnmanagedHosts = true
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			updatedAppIngress.Spec.Template.Spec.Rules[0].Host = "foreign.example.com"
+			updatedAppIngress.Spec.AdoptionPolicy = ingressv1alpha1.AdoptionPolicyIfUnowned
+			Expect(k8sClient.Update(ctx, updatedAppIngress)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
 			Expect(err).NotTo(HaveOccurred())
 
-			// Update AppIngress template
-			updatedHost := "updated-example.com"
+			adoptedIngress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreignIngress), adoptedIngress)).To(Succeed())
+			Expect(adoptedIngress.Annotations).To(HaveKeyWithValue(ingressv1alpha1.SourceAnnotation, namespace+"/"+resourceName))
+
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			hostCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeHostConflict)
+			Expect(hostCondition).NotTo(BeNil())
+			Expect(hostCondition.Status).To(Equal(metav1.ConditionFalse))
+		})
+
+		It("should never adopt an ingress created by a ResourceDuplicator", func() {
+			foreignIngress.Labels = map[string]string{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue}
+			foreignIngress.Annotations = map[string]string{ingressv1alpha1.DuplicatorAnnotation: targetNs + "/duplicator"}
+			Expect(k8sClient.Update(ctx, foreignIngress)).To(Succeed())
+
 			updatedAppIngress := &ingressv1alpha1.AppIngress{}
 			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
-			updatedAppIngress.Spec.Template.Spec.Rules[0].Host = updatedHost
+			updatedAppIngress.Spec.AdoptionPolicy = ingressv1alpha1.AdoptionPolicyAlways
 			Expect(k8sClient.Update(ctx, updatedAppIngress)).To(Succeed())
 
-			// Second reconciliation to update ingress
-			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{
-				NamespacedName: namespacedName,
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			conflictCondition := findCondition(updatedAppIngress.Status.Conditions, ConditionTypeIngressConflict)
+			Expect(conflictCondition).NotTo(BeNil())
+			Expect(conflictCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(conflictCondition.Reason).To(Equal("OwnedByOther"))
+			Expect(conflictCondition.Message).To(ContainSubstring("ResourceDuplicator " + targetNs + "/duplicator"))
+
+			existingIngress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreignIngress), existingIngress)).To(Succeed())
+			Expect(existingIngress.Spec.Rules[0].Host).To(Equal("foreign.example.com"))
+			Expect(existingIngress.Annotations).NotTo(HaveKey(ingressv1alpha1.SourceAnnotation))
+		})
+
+		It("should not overwrite an ingress managed by another AppIngress", func() {
+			// Let the first AppIngress adopt the Ingress
+			updatedAppIngress := &ingressv1alpha1.AppIngress{}
+			Expect(k8sClient.Get(ctx, namespacedName, updatedAppIngress)).To(Succeed())
+			updatedAppIngress.Spec.AdoptionPolicy = ingressv1alpha1.AdoptionPolicyIfUnowned
+			Expect(k8sClient.Update(ctx, updatedAppIngress)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			// A second AppIngress targeting the same name is blocked even with IfUnowned
+			otherName := types.NamespacedName{Name: "other-appingress", Namespace: namespace}
+			other := &ingressv1alpha1.AppIngress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      otherName.Name,
+					Namespace: otherName.Namespace,
+				},
+				Spec: ingressv1alpha1.AppIngressSpec{
+					Template: ingressv1alpha1.IngressTemplate{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: foreignIngressName,
+						},
+						Spec: networkingv1.IngressSpec{
+							Rules: []networkingv1.IngressRule{
+								{
+									Host: "other.example.com",
+								},
+							},
+						},
+					},
+					TargetNamespace: targetNs,
+					AdoptionPolicy:  ingressv1alpha1.AdoptionPolicyIfUnowned,
+				},
+			}
+			Expect(k8sClient.Create(ctx, other)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: otherName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, otherName, other)).To(Succeed())
+			conflictCondition := findCondition(other.Status.Conditions, ConditionTypeIngressConflict)
+			Expect(conflictCondition).NotTo(BeNil())
+			Expect(conflictCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(conflictCondition.Reason).To(Equal("OwnedByOther"))
+
+			existingIngress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreignIngress), existingIngress)).To(Succeed())
+			Expect(existingIngress.Spec.Rules[0].Host).To(Equal("example.com"))
+
+			// Clean up the second AppIngress, it must not delete the Ingress it does not own
+			Expect(k8sClient.Delete(ctx, other)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: otherName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreignIngress), existingIngress)).To(Succeed())
+		})
+	})
+
+	Context("When using a ClusterAppIngress", func() {
+		var (
+			clusterKey        = types.NamespacedName{Name: "test-clusterappingress"}
+			clusterAppIngress *ingressv1alpha1.ClusterAppIngress
+		)
+
+		BeforeEach(func() {
+			clusterAppIngress = &ingressv1alpha1.ClusterAppIngress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: clusterKey.Name,
+				},
+				Spec: ingressv1alpha1.AppIngressSpec{
+					Template: ingressv1alpha1.IngressTemplate{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "cluster-ingress",
+						},
+						Spec: networkingv1.IngressSpec{
+							Rules: []networkingv1.IngressRule{
+								{
+									Host: "status.example.com",
+								},
+							},
+						},
+					},
+					TargetNamespace: targetNs,
+				},
+			}
+			Expect(k8sClient.Create(ctx, clusterAppIngress)).To(Succeed())
+
+			controllerReconciler = &AppIngressReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				Recorder:       record.NewFakeRecorder(100),
+				RequireConsent: true,
+			}
+		})
+
+		AfterEach(func() {
+			if clusterAppIngress != nil {
+				_ = k8sClient.Delete(ctx, clusterAppIngress)
+				_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+				Expect(err).NotTo(HaveOccurred())
+				clusterAppIngress = nil
+			}
+		})
+
+		It("should create and clean up the ingress without namespace consent", func() {
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+			Expect(err).NotTo(HaveOccurred())
+
+			ingressKey := types.NamespacedName{Name: "cluster-ingress", Namespace: targetNs}
+			createdIngress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, ingressKey, createdIngress)).To(Succeed())
+			Expect(createdIngress.Annotations).To(HaveKeyWithValue(ingressv1alpha1.SourceAnnotation, clusterKey.Name))
+			Expect(controllerReconciler.findAppIngressForIngress(ctx, createdIngress)).To(ConsistOf(
+				ctrl.Request{NamespacedName: clusterKey}))
+
+			updated := &ingressv1alpha1.ClusterAppIngress{}
+			Expect(k8sClient.Get(ctx, clusterKey, updated)).To(Succeed())
+			Expect(updated.Status.Ingresses).To(ConsistOf(ingressv1alpha1.ResourceReference{
+				Namespace: targetNs, Name: "cluster-ingress",
+			}))
+
+			By("deleting the ingress together with the ClusterAppIngress")
+			Expect(k8sClient.Delete(ctx, clusterAppIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+			Expect(err).NotTo(HaveOccurred())
+			clusterAppIngress = nil
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, ingressKey, createdIngress))).To(BeTrue())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, clusterKey, updated))).To(BeTrue())
+		})
+
+		It("should not write into denied namespaces", func() {
+			controllerReconciler.DeniedNamespaces = []string{targetNs}
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+			Expect(err).NotTo(HaveOccurred())
+
+			ingressKey := types.NamespacedName{Name: "cluster-ingress", Namespace: targetNs}
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, ingressKey, &networkingv1.Ingress{}))).To(BeTrue())
+			updated := &ingressv1alpha1.ClusterAppIngress{}
+			Expect(k8sClient.Get(ctx, clusterKey, updated)).To(Succeed())
+			policyCondition := findCondition(updated.Status.Conditions, ConditionTypePolicyAllowed)
+			Expect(policyCondition).NotTo(BeNil())
+			Expect(policyCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(policyCondition.Message).To(ContainSubstring("may not be targeted by a ClusterAppIngress"))
+		})
+
+		It("should bridge backend services from any namespace", func() {
+			backend := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "status-page", Namespace: namespace},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, backend)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, backend)).To(Succeed())
 			})
+
+			pathType := networkingv1.PathTypePrefix
+			Expect(k8sClient.Get(ctx, clusterKey, clusterAppIngress)).To(Succeed())
+			clusterAppIngress.Spec.BackendNamespace = namespace
+			clusterAppIngress.Spec.Template.Spec.Rules[0].HTTP = &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{
+					Path:     "/",
+					PathType: &pathType,
+					Backend: networkingv1.IngressBackend{
+						Service: &networkingv1.IngressServiceBackend{
+							Name: "status-page",
+							Port: networkingv1.ServiceBackendPort{Name: "http"},
+						},
+					},
+				}},
+			}
+			Expect(k8sClient.Update(ctx, clusterAppIngress)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
 			Expect(err).NotTo(HaveOccurred())
 
-			// Verify ingress update
-			updatedIngress := &networkingv1.Ingress{}
-			err = k8sClient.Get(ctx, types.NamespacedName{
-				Name:      appIngress.Spec.Template.Name,
-				Namespace: targetNs,
-			}, updatedIngress)
+			serviceKey := types.NamespacedName{Name: "status-page", Namespace: targetNs}
+			bridged := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, serviceKey, bridged)).To(Succeed())
+			Expect(bridged.Spec.Type).To(Equal(corev1.ServiceTypeExternalName))
+			Expect(bridged.Spec.ExternalName).To(Equal("status-page." + namespace + ".svc.cluster.local"))
+			Expect(bridged.Annotations).To(HaveKeyWithValue(ingressv1alpha1.SourceAnnotation, clusterKey.Name))
+
+			updated := &ingressv1alpha1.ClusterAppIngress{}
+			Expect(k8sClient.Get(ctx, clusterKey, updated)).To(Succeed())
+			Expect(updated.Status.BackendServices).To(ConsistOf(ingressv1alpha1.ResourceReference{
+				Namespace: targetNs, Name: "status-page",
+			}))
+			backendsCondition := findCondition(updated.Status.Conditions, ConditionTypeBackendsSynced)
+			Expect(backendsCondition).NotTo(BeNil())
+			Expect(backendsCondition.Status).To(Equal(metav1.ConditionTrue))
+
+			By("deleting the bridged service together with the ClusterAppIngress")
+			Expect(k8sClient.Delete(ctx, clusterAppIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(updatedIngress.Spec.Rules[0].Host).To(Equal(updatedHost))
+			clusterAppIngress = nil
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, serviceKey, &corev1.Service{}))).To(BeTrue())
 		})
 	})
 
@@ -264,8 +1695,9 @@ var _ = Describe("AppIngress Controller", Ordered, func() {
 
 			// Create controller instance
 			controllerReconciler = &AppIngressReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
 			}
 
 			// First reconciliation to create ingress and add finalizer
@@ -328,6 +1760,36 @@ var _ = Describe("AppIngress Controller", Ordered, func() {
 			Expect(apierrors.IsNotFound(err)).To(BeTrue())
 		})
 
+		It("should complete deletion when the Gateway API is disabled", func() {
+			// HTTPRoutes recorded while the Gateway API was enabled cannot be looked up without it
+			Expect(k8sClient.Get(ctx, namespacedName, appIngress)).To(Succeed())
+			appIngress.Status.HTTPRoutes = []ingressv1alpha1.ResourceReference{
+				{Namespace: targetNs, Name: appIngress.Spec.Template.Name},
+			}
+			Expect(k8sClient.Status().Update(ctx, appIngress)).To(Succeed())
+			withWatch, err := client.NewWithWatch(cfg, client.Options{Scheme: k8sClient.Scheme()})
+			Expect(err).NotTo(HaveOccurred())
+			controllerReconciler.Client = interceptor.NewClient(withWatch, interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+					opts ...client.GetOption) error {
+					if _, ok := obj.(*gatewayv1.HTTPRoute); ok {
+						return &meta.NoKindMatchError{GroupKind: gatewayv1.SchemeGroupVersion.WithKind("HTTPRoute").GroupKind()}
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			})
+
+			Expect(k8sClient.Delete(ctx, appIngress)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: appIngress.Spec.Template.Name, Namespace: targetNs},
+				&networkingv1.Ingress{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			err = k8sClient.Get(ctx, namespacedName, &ingressv1alpha1.AppIngress{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
 		It("should handle deletion when ingress is already gone", func() {
 			// Delete ingress manually first
 			ingress := &networkingv1.Ingress{
@@ -358,6 +1820,20 @@ var _ = Describe("AppIngress Controller", Ordered, func() {
 })
 
 // Helper function to find a condition by type
+// createBackendService creates the Service the test template routes to in the namespace
+func createBackendService(namespace string) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: namespace},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Port: 80}},
+		},
+	}
+	Expect(k8sClient.Create(ctx, service)).To(Succeed())
+	DeferCleanup(func() {
+		Expect(k8sClient.Delete(ctx, service)).To(Succeed())
+	})
+}
+
 func findCondition(conditions []metav1.Condition, conditionType string) *metav1.Condition {
 	for i := range conditions {
 		if conditions[i].Type == conditionType {
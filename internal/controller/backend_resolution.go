@@ -0,0 +1,159 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+// backendIndexKey indexes AppIngresses by the names of the Services used as backends by their template
+const backendIndexKey = ".spec.template.spec.backend.service.name"
+
+// serviceBackends returns the Service backends of the template, without duplicates
+func serviceBackends(appIngress ingressv1alpha1.AppIngressObject) []networkingv1.IngressServiceBackend {
+	var backends []networkingv1.IngressServiceBackend
+	add := func(backend *networkingv1.IngressBackend) {
+		if backend != nil && backend.Service != nil && !slices.Contains(backends, *backend.Service) {
+			backends = append(backends, *backend.Service)
+		}
+	}
+	spec := &appIngress.GetSpec().Template.Spec
+	add(spec.DefaultBackend)
+	for _, rule := range spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			add(&path.Backend)
+		}
+	}
+	return backends
+}
+
+// backendPort returns the name of the Service port, or its number when it is referenced by number
+func backendPort(port networkingv1.ServiceBackendPort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return strconv.Itoa(int(port.Number))
+}
+
+// exposesPort reports whether the Service has the port referenced by the backend. An ExternalName
+// Service without ports forwards any port, so every number is accepted.
+func exposesPort(service *corev1.Service, port networkingv1.ServiceBackendPort) bool {
+	if service.Spec.Type == corev1.ServiceTypeExternalName && len(service.Spec.Ports) == 0 {
+		return port.Name == ""
+	}
+	return slices.ContainsFunc(service.Spec.Ports, func(servicePort corev1.ServicePort) bool {
+		if port.Name != "" {
+			return servicePort.Name == port.Name
+		}
+		return servicePort.Port == port.Number
+	})
+}
+
+// resolveBackends checks that every backend Service port of the template exists in the target
+// namespace. Bridged Services count, so this runs after the backends are bridged.
+func (r *AppIngressReconciler) resolveBackends(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	namespace string) ([]ingressv1alpha1.BackendStatus, error) {
+	var statuses []ingressv1alpha1.BackendStatus
+	for _, backend := range serviceBackends(appIngress) {
+		status := ingressv1alpha1.BackendStatus{
+			Namespace: namespace,
+			Service:   backend.Name,
+			Port:      backendPort(backend.Port),
+			Status:    metav1.ConditionTrue,
+			Reason:    "Resolved",
+			Message:   "Service port exists",
+		}
+		service := &corev1.Service{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: backend.Name}, service); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			status.Status, status.Reason = metav1.ConditionFalse, "ServiceNotFound"
+			status.Message = fmt.Sprintf("Service %s/%s does not exist", namespace, backend.Name)
+		} else if !exposesPort(service, backend.Port) {
+			status.Status, status.Reason = metav1.ConditionFalse, "PortNotFound"
+			status.Message = fmt.Sprintf("Service %s/%s has no port %s", namespace, backend.Name, status.Port)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// setBackendsResolvedCondition reports whether every backend Service port exists in the target
+// namespaces, surfacing the reason of the first missing one. It reports whether the condition changed.
+func setBackendsResolvedCondition(appIngress ingressv1alpha1.AppIngressObject, backends []ingressv1alpha1.BackendStatus) bool {
+	condition := metav1.Condition{
+		Type:    ConditionTypeBackendsResolved,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Resolved",
+		Message: "Every backend Service port exists in the target namespaces",
+	}
+	var messages []string
+	for _, backend := range backends {
+		if backend.Status == metav1.ConditionTrue {
+			continue
+		}
+		if len(messages) == 0 {
+			condition.Status, condition.Reason = metav1.ConditionFalse, backend.Reason
+		}
+		messages = append(messages, backend.Message)
+	}
+	if len(messages) > 0 {
+		condition.Message = strings.Join(messages, "; ")
+	}
+	return setCondition(appIngress, condition)
+}
+
+// findAppIngressesForService maps a Service to the AppIngresses bridging it or routing to it in its
+// namespace, so the BackendsResolved condition follows Services as they come and go
+func (r *AppIngressReconciler) findAppIngressesForService(ctx context.Context, obj client.Object) []reconcile.Request {
+	requests := r.findAppIngressesForCopy(backendServiceIndexKey)(ctx, obj)
+
+	appIngresses, err := r.listAppIngresses(ctx, client.MatchingFields{backendIndexKey: obj.GetName()})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list AppIngresses for Service",
+			"service", client.ObjectKeyFromObject(obj))
+		return requests
+	}
+	for _, appIngress := range appIngresses {
+		if !targetsNamespace(appIngress, obj.GetNamespace()) {
+			continue
+		}
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(appIngress)}
+		if !slices.Contains(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
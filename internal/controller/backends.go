@@ -0,0 +1,347 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+// backendServiceIndexKey indexes AppIngresses by the "<namespace>/<name>" of the Services they bridge
+const backendServiceIndexKey = ".spec.backendNamespace"
+
+// defaultClusterDomain is the DNS domain of the cluster used when none is configured
+const defaultClusterDomain = "cluster.local"
+
+// backendServiceNames returns the Services used as backends by the template, without duplicates
+func backendServiceNames(appIngress ingressv1alpha1.AppIngressObject) []string {
+	var names []string
+	for _, backend := range serviceBackends(appIngress) {
+		if !slices.Contains(names, backend.Name) {
+			names = append(names, backend.Name)
+		}
+	}
+	return names
+}
+
+// backendServiceSources returns the "<namespace>/<name>" keys of the Services bridged by the AppIngress
+func backendServiceSources(appIngress ingressv1alpha1.AppIngressObject) []string {
+	if appIngress.GetSpec().BackendNamespace == "" {
+		return nil
+	}
+	var keys []string
+	for _, name := range backendServiceNames(appIngress) {
+		keys = append(keys, appIngress.GetSpec().BackendNamespace+"/"+name)
+	}
+	return keys
+}
+
+// backendServiceRefs returns the references of the bridged Services in the given target namespace.
+// Nothing is bridged into the backend namespace itself, or when invalidBackendNamespace rejects it.
+func backendServiceRefs(appIngress ingressv1alpha1.AppIngressObject, namespace string) []ingressv1alpha1.ResourceReference {
+	backendNamespace := appIngress.GetSpec().BackendNamespace
+	if backendNamespace == "" || backendNamespace == namespace || invalidBackendNamespace(appIngress) != nil {
+		return nil
+	}
+	var refs []ingressv1alpha1.ResourceReference
+	for _, name := range backendServiceNames(appIngress) {
+		refs = append(refs, ingressv1alpha1.ResourceReference{Namespace: namespace, Name: name})
+	}
+	return refs
+}
+
+// invalidBackendNamespace returns an error when the AppIngress bridges backends from a namespace
+// other than its own, which would expose Services it has no access to
+func invalidBackendNamespace(appIngress ingressv1alpha1.AppIngressObject) error {
+	// ClusterAppIngresses are created by cluster administrators and may bridge from any namespace
+	backendNamespace := appIngress.GetSpec().BackendNamespace
+	if backendNamespace == "" || appIngress.GetNamespace() == "" || backendNamespace == appIngress.GetNamespace() {
+		return nil
+	}
+	return &syncError{
+		reason: "InvalidNamespace",
+		message: fmt.Sprintf("backendNamespace %s must be the namespace of the AppIngress",
+			appIngress.GetSpec().BackendNamespace),
+	}
+}
+
+// reconcileBackends bridges the backend Services of the AppIngress into the target namespace. It
+// returns the Services that may exist afterwards, so they are tracked even when some of them failed,
+// and the errors per Service.
+func (r *AppIngressReconciler) reconcileBackends(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	namespace string) ([]ingressv1alpha1.ResourceReference, []error) {
+	refs := backendServiceRefs(appIngress, namespace)
+	var errs []error
+	for _, ref := range refs {
+		if err := r.reconcileBackendService(ctx, appIngress, ref); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return refs, errs
+}
+
+// reconcileBackendService applies a Service routing to the backend Service, together with mirrored
+// EndpointSlices in the EndpointSlice mode. A Service bridged for another AppIngress from the same
+// backend is left alone, while any other Service with the same name is never overwritten.
+func (r *AppIngressReconciler) reconcileBackendService(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	ref ingressv1alpha1.ResourceReference) error {
+	backendNamespace := appIngress.GetSpec().BackendNamespace
+	copiedFrom := backendNamespace + "/" + ref.Name
+
+	source := &corev1.Service{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: backendNamespace, Name: ref.Name}, source); err != nil {
+		if apierrors.IsNotFound(err) {
+			return &syncError{
+				reason:  "SourceNotFound",
+				message: fmt.Sprintf("backend Service %s does not exist", copiedFrom),
+			}
+		}
+		return err
+	}
+
+	existing := &corev1.Service{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	} else if !ingressv1alpha1.IsManagedBy(existing, sourceKey(appIngress)) {
+		if ingressv1alpha1.IsManaged(existing) && existing.Annotations[ingressv1alpha1.CopiedFromAnnotation] == copiedFrom {
+			// Bridged for another AppIngress from the same backend, which keeps it in sync
+			return nil
+		}
+		return &syncError{
+			reason:  "Conflict",
+			message: fmt.Sprintf("Service %s/%s already exists and does not bridge %s", ref.Namespace, ref.Name, copiedFrom),
+		}
+	}
+
+	service := r.bridgeService(appIngress, ref, source)
+	// The type of a Service cannot always be changed in place, so it is recreated when the mode changes
+	if existing.ResourceVersion != "" && existing.Spec.Type != service.Spec.Type {
+		if err := r.Delete(ctx, existing, client.Preconditions{UID: &existing.UID}); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	if err := r.Patch(ctx, service, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+
+	var sources []discoveryv1.EndpointSlice
+	if appIngress.GetSpec().BackendBridgeMode == ingressv1alpha1.BackendBridgeModeEndpointSlice {
+		sourceSlices := &discoveryv1.EndpointSliceList{}
+		if err := r.List(ctx, sourceSlices, client.InNamespace(backendNamespace),
+			client.MatchingLabels{discoveryv1.LabelServiceName: ref.Name}); err != nil {
+			return err
+		}
+		sources = sourceSlices.Items
+	}
+	return r.syncEndpointSlices(ctx, appIngress, ref, sources)
+}
+
+// bridgeService returns the Service bridging the backend Service into the target namespace. In the
+// ExternalName mode it resolves to the backend Service; in the EndpointSlice mode it has no selector
+// and its endpoints are mirrored from the backend.
+func (r *AppIngressReconciler) bridgeService(appIngress ingressv1alpha1.AppIngressObject,
+	ref ingressv1alpha1.ResourceReference, source *corev1.Service) *corev1.Service {
+	mirror := appIngress.GetSpec().BackendBridgeMode == ingressv1alpha1.BackendBridgeModeEndpointSlice
+
+	// Ingress backends refer to Service ports by number or name, so both are kept. Mirrored
+	// endpoints carry the target ports of the backend.
+	ports := make([]corev1.ServicePort, 0, len(source.Spec.Ports))
+	for _, port := range source.Spec.Ports {
+		bridged := corev1.ServicePort{
+			Name:        port.Name,
+			Protocol:    port.Protocol,
+			AppProtocol: port.AppProtocol,
+			Port:        port.Port,
+		}
+		if mirror {
+			bridged.TargetPort = port.TargetPort
+		}
+		ports = append(ports, bridged)
+	}
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.Name,
+			Namespace: ref.Namespace,
+			Labels:    map[string]string{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue},
+			Annotations: map[string]string{
+				ingressv1alpha1.SourceAnnotation:     sourceKey(appIngress),
+				ingressv1alpha1.CopiedFromAnnotation: source.Namespace + "/" + source.Name,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeClusterIP,
+			Ports: ports,
+		},
+	}
+	if !mirror {
+		clusterDomain := r.ClusterDomain
+		if clusterDomain == "" {
+			clusterDomain = defaultClusterDomain
+		}
+		service.Spec.Type = corev1.ServiceTypeExternalName
+		service.Spec.ExternalName = fmt.Sprintf("%s.%s.svc.%s", source.Name, source.Namespace, clusterDomain)
+	}
+	return service
+}
+
+// syncEndpointSlices mirrors the EndpointSlices of the backend Service into the bridged Service,
+// including their ports and endpoint conditions, and deletes mirrored slices whose source is gone.
+// Slices with the same name that were not mirrored for the AppIngress are never overwritten.
+// Passing no sources removes every slice mirrored for the bridged Service.
+func (r *AppIngressReconciler) syncEndpointSlices(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	ref ingressv1alpha1.ResourceReference, sources []discoveryv1.EndpointSlice) error {
+	existing := &discoveryv1.EndpointSliceList{}
+	if err := r.List(ctx, existing, client.InNamespace(ref.Namespace), client.MatchingLabels{
+		discoveryv1.LabelServiceName: ref.Name,
+		discoveryv1.LabelManagedBy:   ingressv1alpha1.ManagedByValue,
+	}); err != nil {
+		return err
+	}
+
+	// Check every slice first, so a conflict leaves the mirrored slices as they are
+	var mirrored []discoveryv1.EndpointSlice
+	for _, source := range sources {
+		copiedFrom := source.Namespace + "/" + source.Name
+		current := &discoveryv1.EndpointSlice{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: source.Name}, current); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+		} else if !ingressv1alpha1.IsManagedBy(current, sourceKey(appIngress)) {
+			if ingressv1alpha1.IsManaged(current) && current.Annotations[ingressv1alpha1.CopiedFromAnnotation] == copiedFrom {
+				// Mirrored for another AppIngress from the same backend, which keeps it in sync
+				continue
+			}
+			return &syncError{
+				reason: "Conflict",
+				message: fmt.Sprintf("EndpointSlice %s/%s already exists and does not mirror %s",
+					ref.Namespace, source.Name, copiedFrom),
+			}
+		}
+		mirrored = append(mirrored, source)
+	}
+
+	var errs []error
+	names := make([]string, 0, len(mirrored))
+	for _, source := range mirrored {
+		names = append(names, source.Name)
+		slice := &discoveryv1.EndpointSlice{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: discoveryv1.SchemeGroupVersion.String(),
+				Kind:       "EndpointSlice",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      source.Name,
+				Namespace: ref.Namespace,
+				Labels: map[string]string{
+					discoveryv1.LabelServiceName:   ref.Name,
+					discoveryv1.LabelManagedBy:     ingressv1alpha1.ManagedByValue,
+					ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue,
+				},
+				Annotations: map[string]string{
+					ingressv1alpha1.SourceAnnotation:     sourceKey(appIngress),
+					ingressv1alpha1.CopiedFromAnnotation: source.Namespace + "/" + source.Name,
+				},
+			},
+			AddressType: source.AddressType,
+			Endpoints:   source.DeepCopy().Endpoints,
+			Ports:       source.DeepCopy().Ports,
+		}
+		if err := r.Patch(ctx, slice, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, slice := range existing.Items {
+		if slices.Contains(names, slice.Name) || !ingressv1alpha1.IsManagedBy(&slice, sourceKey(appIngress)) {
+			continue
+		}
+		if err := r.Delete(ctx, &slice, client.Preconditions{UID: &slice.UID}); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, err)
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// cleanupBackends deletes the referenced bridged Services and their mirrored EndpointSlices, except
+// the ones in keep. It returns the references that could not be deleted so they can be retried.
+func (r *AppIngressReconciler) cleanupBackends(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	refs, keep []ingressv1alpha1.ResourceReference) ([]ingressv1alpha1.ResourceReference, error) {
+	var errs []error
+	failed, err := deleteUnkept(refs, keep, func(ref ingressv1alpha1.ResourceReference) error {
+		return r.syncEndpointSlices(ctx, appIngress, ref, nil)
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	remaining, err := r.cleanupCopies(ctx, appIngress, "Service", newService, refs, slices.Concat(keep, failed))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return append(remaining, failed...), kerrors.NewAggregate(errs)
+}
+
+// findAppIngressesForEndpointSlice maps an EndpointSlice of a backend Service to the AppIngresses
+// mirroring it, and a mirrored EndpointSlice back to its AppIngress so changes are reverted
+func (r *AppIngressReconciler) findAppIngressesForEndpointSlice(ctx context.Context,
+	obj client.Object) []reconcile.Request {
+	if ingressv1alpha1.IsManaged(obj) {
+		namespace, name, err := cache.SplitMetaNamespaceKey(obj.GetAnnotations()[ingressv1alpha1.SourceAnnotation])
+		if err != nil || name == "" {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+	}
+
+	serviceName := obj.GetLabels()[discoveryv1.LabelServiceName]
+	if serviceName == "" {
+		return nil
+	}
+	appIngresses, err := r.listAppIngresses(ctx,
+		client.MatchingFields{backendServiceIndexKey: obj.GetNamespace() + "/" + serviceName})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list AppIngresses for EndpointSlice",
+			"endpointSlice", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, appIngress := range appIngresses {
+		if appIngress.GetSpec().BackendBridgeMode == ingressv1alpha1.BackendBridgeModeEndpointSlice {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(appIngress)})
+		}
+	}
+	return requests
+}
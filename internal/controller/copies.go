@@ -0,0 +1,130 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+// syncError is returned when an object cannot be copied into a target namespace until someone
+// changes the source or the target, so retrying right away does not help
+type syncError struct {
+	reason  string
+	message string
+}
+
+func (e *syncError) Error() string {
+	return e.message
+}
+
+// setSyncCondition reports whether the objects copied into the target namespaces are in sync. The
+// condition is True with reason NotConfigured when notConfigured is set, and False with the reason
+// of the first error otherwise. It reports whether the condition changed.
+func setSyncCondition(appIngress ingressv1alpha1.AppIngressObject, conditionType, synced, notConfigured string,
+	errs []error) bool {
+	condition := metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Synced",
+		Message: synced,
+	}
+	switch {
+	case len(errs) > 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "Error"
+		var syncErr *syncError
+		if errors.As(errs[0], &syncErr) {
+			condition.Reason = syncErr.reason
+		}
+		messages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		condition.Message = strings.Join(messages, "; ")
+	case notConfigured != "":
+		condition.Reason, condition.Message = "NotConfigured", notConfigured
+	}
+	return setCondition(appIngress, condition)
+}
+
+// newSecret and newService create empty objects for cleanupCopies
+func newSecret() client.Object  { return &corev1.Secret{} }
+func newService() client.Object { return &corev1.Service{} }
+
+// cleanupCopies deletes the referenced objects copied into target namespaces, except the ones in
+// keep. It returns the references that could not be deleted so they can be retried.
+func (r *AppIngressReconciler) cleanupCopies(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	kind string, newObj func() client.Object, refs, keep []ingressv1alpha1.ResourceReference,
+) ([]ingressv1alpha1.ResourceReference, error) {
+	return deleteUnkept(refs, keep, func(ref ingressv1alpha1.ResourceReference) error {
+		return r.deleteCopy(ctx, appIngress, kind, newObj(), ref)
+	})
+}
+
+// deleteCopy deletes the referenced object if it was copied for the AppIngress. Objects that are
+// gone or belong to someone else are left alone.
+func (r *AppIngressReconciler) deleteCopy(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	kind string, obj client.Object, ref ingressv1alpha1.ResourceReference) error {
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !ingressv1alpha1.IsManagedBy(obj, sourceKey(appIngress)) {
+		return nil
+	}
+	uid := obj.GetUID()
+	if err := r.Delete(ctx, obj, client.Preconditions{UID: &uid}); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	log.FromContext(ctx).Info("Deleted copy", "kind", kind, "object", ref)
+	return nil
+}
+
+// findAppIngressesForCopy returns a map function that maps a source object, or a copy of one, to the
+// AppIngresses listing its "<namespace>/<name>" under the given index. Changes of the source are
+// propagated this way, and deleted or modified copies are restored.
+func (r *AppIngressReconciler) findAppIngressesForCopy(indexKey string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		key := obj.GetNamespace() + "/" + obj.GetName()
+		if ingressv1alpha1.IsManaged(obj) {
+			if copiedFrom := obj.GetAnnotations()[ingressv1alpha1.CopiedFromAnnotation]; copiedFrom != "" {
+				key = copiedFrom
+			}
+		}
+
+		appIngresses, err := r.listAppIngressesIndexed(ctx, indexKey, key)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list AppIngresses for copied object",
+				"object", client.ObjectKeyFromObject(obj))
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(appIngresses))
+		for _, appIngress := range appIngresses {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(appIngress)})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/httproute"
+)
+
+// errGatewayAPIDisabled is returned for AppIngresses generating HTTPRoutes while the controller
+// does not watch them
+var errGatewayAPIDisabled = errors.New("support for the Gateway API is disabled, start the controller with --enable-gateway-api")
+
+// outputKind returns the kind of object generated for the AppIngress
+func outputKind(appIngress ingressv1alpha1.AppIngressObject) ingressv1alpha1.OutputKind {
+	if appIngress.GetSpec().OutputKind == "" {
+		return ingressv1alpha1.OutputKindIngress
+	}
+	return appIngress.GetSpec().OutputKind
+}
+
+// newHTTPRoute creates an empty HTTPRoute for cleanupCopies
+func newHTTPRoute() client.Object { return &gatewayv1.HTTPRoute{} }
+
+// reconcileHTTPRoute applies the HTTPRoute translated from the template in a single target namespace
+// and returns it together with the operation that was performed. It follows the same ownership and
+// field conflict rules as the Ingress.
+func (r *AppIngressReconciler) reconcileHTTPRoute(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	ref ingressv1alpha1.ResourceReference) (*gatewayv1.HTTPRoute, controllerutil.OperationResult, error) {
+	existing := &gatewayv1.HTTPRoute{}
+	if !r.GatewayAPI {
+		return existing, controllerutil.OperationResultNone, errGatewayAPIDisabled
+	}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return existing, controllerutil.OperationResultNone, err
+		}
+	}
+	if err := checkOwnership(appIngress, ingressv1alpha1.OutputKindHTTPRoute, existing); err != nil {
+		return existing, controllerutil.OperationResultNone, err
+	}
+
+	var parentRefs []gatewayv1.ParentReference
+	if appIngress.GetSpec().HTTPRoute != nil {
+		parentRefs = appIngress.GetSpec().HTTPRoute.ParentRefs
+	}
+	spec, errs := httproute.FromIngress(&appIngress.GetSpec().Template.Spec, parentRefs,
+		field.NewPath("spec", "template", "spec"))
+	if len(errs) > 0 {
+		return existing, controllerutil.OperationResultNone, errs.ToAggregate()
+	}
+	route := &gatewayv1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayv1.GroupVersion.String(),
+			Kind:       "HTTPRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ref.Name,
+			Namespace:   ref.Namespace,
+			Labels:      managedLabels(appIngress),
+			Annotations: managedAnnotations(appIngress),
+		},
+		Spec: *spec,
+	}
+	result, err := r.applyOutput(ctx, appIngress, ingressv1alpha1.OutputKindHTTPRoute, route, existing)
+	if err != nil {
+		return existing, result, err
+	}
+	return route, result, nil
+}
+
+// routeAccepted reports whether a parent Gateway accepted the HTTPRoute
+func routeAccepted(route *gatewayv1.HTTPRoute) bool {
+	return slices.ContainsFunc(route.Status.Parents, func(parent gatewayv1.RouteParentStatus) bool {
+		return meta.IsStatusConditionTrue(parent.Conditions, string(gatewayv1.RouteConditionAccepted))
+	})
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+// Outcomes counted by ingressOperations
+const (
+	operationCreate   = "create"
+	operationUpdate   = "update"
+	operationDelete   = "delete"
+	operationConflict = "conflict"
+)
+
+var (
+	// ingressOperations counts the outcomes of writing generated Ingresses
+	ingressOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingress_duplicator_ingress_operations_total",
+		Help: "Number of generated Ingresses created, updated, deleted or blocked by a conflict",
+	}, []string{"operation"})
+
+	// ingressApplyDuration measures how long it takes for a new AppIngress generation to be applied
+	ingressApplyDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "ingress_duplicator_ingress_apply_duration_seconds",
+		Help:    "Time from an AppIngress generation change until its Ingresses are applied in every target namespace",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+
+	// orphanedIngresses counts the Ingresses and HTTPRoutes the last sweep found left behind by their source
+	orphanedIngresses = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_duplicator_orphaned_ingresses",
+		Help: "Number of managed Ingresses and HTTPRoutes whose source was deleted or recreated without them, as of the last sweep",
+	}, []string{"kind", "reason"})
+
+	// orphanedIngressesDeleted counts the orphaned Ingresses and HTTPRoutes deleted by the sweeper
+	orphanedIngressesDeleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingress_duplicator_orphaned_ingresses_deleted_total",
+		Help: "Number of orphaned Ingresses and HTTPRoutes deleted by the orphan sweeper",
+	}, []string{"kind"})
+
+	managedIngressesDesc = prometheus.NewDesc(
+		"ingress_duplicator_managed_ingresses",
+		"Number of Ingresses managed by the controller per target namespace",
+		[]string{"namespace"}, nil,
+	)
+	namespaceInvalidDesc = prometheus.NewDesc(
+		"ingress_duplicator_appingresses_namespace_invalid",
+		"Number of AppIngresses and ClusterAppIngresses whose NamespaceValid condition is False",
+		nil, nil,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(ingressOperations, ingressApplyDuration, orphanedIngresses, orphanedIngressesDeleted,
+		state)
+}
+
+// state is registered once with the other metrics, SetupWithManager points it at the manager's cache
+var state = &stateCollector{}
+
+// stateCollector reports gauges computed from the cached Ingresses and AppIngresses at scrape time,
+// so they stay correct regardless of which AppIngress contributed to them
+type stateCollector struct {
+	mu     sync.RWMutex
+	reader client.Reader
+}
+
+// setReader sets the reader the gauges are computed from
+func (c *stateCollector) setReader(reader client.Reader) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reader = reader
+}
+
+// Describe implements prometheus.Collector
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- managedIngressesDesc
+	ch <- namespaceInvalidDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	reader := c.reader
+	c.mu.RUnlock()
+	// Nothing to report until a manager is set up
+	if reader == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ingresses := &networkingv1.IngressList{}
+	if err := reader.List(ctx, ingresses,
+		client.MatchingLabels{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue}); err != nil {
+		ch <- prometheus.NewInvalidMetric(managedIngressesDesc, err)
+	} else {
+		counts := map[string]int{}
+		for _, ingress := range ingresses.Items {
+			if ingressv1alpha1.IsManaged(&ingress) {
+				counts[ingress.Namespace]++
+			}
+		}
+		for namespace, count := range counts {
+			ch <- prometheus.MustNewConstMetric(managedIngressesDesc, prometheus.GaugeValue, float64(count), namespace)
+		}
+	}
+
+	appIngresses := &ingressv1alpha1.AppIngressList{}
+	if err := reader.List(ctx, appIngresses); err != nil {
+		ch <- prometheus.NewInvalidMetric(namespaceInvalidDesc, err)
+		return
+	}
+	clusterAppIngresses := &ingressv1alpha1.ClusterAppIngressList{}
+	if err := reader.List(ctx, clusterAppIngresses); err != nil {
+		ch <- prometheus.NewInvalidMetric(namespaceInvalidDesc, err)
+		return
+	}
+	invalid := 0
+	for _, appIngress := range appIngresses.Items {
+		if meta.IsStatusConditionFalse(appIngress.Status.Conditions, ConditionTypeNamespaceValid) {
+			invalid++
+		}
+	}
+	for _, clusterAppIngress := range clusterAppIngresses.Items {
+		if meta.IsStatusConditionFalse(clusterAppIngress.Status.Conditions, ConditionTypeNamespaceValid) {
+			invalid++
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(namespaceInvalidDesc, prometheus.GaugeValue, float64(invalid))
+}
+
+// generationTracker remembers when the controller started working on an AppIngress generation,
+// so the time until it is applied can be observed once every Ingress is in place
+type generationTracker struct {
+	mu      sync.Mutex
+	pending map[types.UID]pendingGeneration
+}
+
+// pendingGeneration is an AppIngress generation that has not been applied yet
+type pendingGeneration struct {
+	generation int64
+	since      time.Time
+}
+
+// start records the current generation of the AppIngress unless it was already applied. Generations
+// are measured from the last change of the spec recorded in the managed fields, or from when they
+// were first reconciled if the managed fields do not tell.
+func (t *generationTracker) start(appIngress ingressv1alpha1.AppIngressObject) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pending, ok := t.pending[appIngress.GetUID()]
+	if ok && pending.generation == appIngress.GetGeneration() {
+		return
+	}
+	if !ok && appIngress.GetStatus().ObservedGeneration == appIngress.GetGeneration() {
+		// Already applied, e.g. before the controller restarted
+		return
+	}
+	if t.pending == nil {
+		t.pending = map[types.UID]pendingGeneration{}
+	}
+	t.pending[appIngress.GetUID()] = pendingGeneration{
+		generation: appIngress.GetGeneration(),
+		since:      specChangedAt(appIngress, time.Now()),
+	}
+}
+
+// specChangedAt returns when the spec of the object was last written according to its managed
+// fields. The first generation falls back to the creation of the object, later ones to now. The
+// managed fields only record seconds and the clock of the API server, so the result is never later
+// than now.
+func specChangedAt(obj metav1.Object, now time.Time) time.Time {
+	var changed time.Time
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Subresource != "" || entry.Time == nil || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		if _, ok := fields["f:spec"]; ok && entry.Time.After(changed) {
+			changed = entry.Time.Time
+		}
+	}
+	if changed.IsZero() && obj.GetGeneration() == 1 {
+		changed = obj.GetCreationTimestamp().Time
+	}
+	if changed.IsZero() || changed.After(now) {
+		return now
+	}
+	return changed
+}
+
+// applied observes the time it took to apply the current generation of the AppIngress
+func (t *generationTracker) applied(appIngress ingressv1alpha1.AppIngressObject) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pending, ok := t.pending[appIngress.GetUID()]
+	if !ok || pending.generation != appIngress.GetGeneration() {
+		return
+	}
+	ingressApplyDuration.Observe(time.Since(pending.since).Seconds())
+	delete(t.pending, appIngress.GetUID())
+}
+
+// forget drops the AppIngress, e.g. after it was deleted
+func (t *generationTracker) forget(uid types.UID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pending, uid)
+}
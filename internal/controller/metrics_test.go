@@ -0,0 +1,72 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+var _ = Describe("specChangedAt", func() {
+	var (
+		now     = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+		created = now.Add(-time.Hour)
+	)
+
+	entry := func(fields string, at time.Time, subresource string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{
+			Manager:     "kubectl",
+			Operation:   metav1.ManagedFieldsOperationUpdate,
+			Time:        &metav1.Time{Time: at},
+			FieldsType:  "FieldsV1",
+			FieldsV1:    &metav1.FieldsV1{Raw: []byte(fields)},
+			Subresource: subresource,
+		}
+	}
+
+	DescribeTable("should measure from the last change of the spec",
+		func(generation int64, managedFields []metav1.ManagedFieldsEntry, expected time.Time) {
+			appIngress := &ingressv1alpha1.AppIngress{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation:        generation,
+					CreationTimestamp: metav1.Time{Time: created},
+					ManagedFields:     managedFields,
+				},
+			}
+			Expect(specChangedAt(appIngress, now)).To(Equal(expected))
+		},
+		Entry("the latest entry writing the spec", int64(2), []metav1.ManagedFieldsEntry{
+			entry(`{"f:spec":{"f:targetNamespace":{}}}`, now.Add(-30*time.Minute), ""),
+			entry(`{"f:spec":{"f:parameters":{}}}`, now.Add(-time.Minute), ""),
+		}, now.Add(-time.Minute)),
+		Entry("ignoring metadata and status writes", int64(2), []metav1.ManagedFieldsEntry{
+			entry(`{"f:spec":{"f:targetNamespace":{}}}`, now.Add(-30*time.Minute), ""),
+			entry(`{"f:metadata":{"f:labels":{"f:spec":{}}}}`, now.Add(-2*time.Minute), ""),
+			entry(`{"f:status":{"f:targets":{}}}`, now.Add(-time.Minute), "status"),
+		}, now.Add(-30*time.Minute)),
+		Entry("the creation of the first generation without managed fields", int64(1), nil, created),
+		Entry("now for later generations without managed fields", int64(3), nil, now),
+		Entry("now when the API server clock is ahead", int64(2), []metav1.ManagedFieldsEntry{
+			entry(`{"f:spec":{"f:targetNamespace":{}}}`, now.Add(time.Second), ""),
+		}, now),
+	)
+})
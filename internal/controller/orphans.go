@@ -0,0 +1,161 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/sourceref"
+)
+
+// OrphanPolicy decides what the OrphanSweeper does with Ingresses left behind by their source
+type OrphanPolicy string
+
+const (
+	// OrphanPolicyReport logs and counts orphaned Ingresses but leaves them in place
+	OrphanPolicyReport OrphanPolicy = "report"
+
+	// OrphanPolicyDelete deletes orphaned Ingresses
+	OrphanPolicyDelete OrphanPolicy = "delete"
+)
+
+// ParseOrphanPolicy validates the value of the --orphan-policy flag
+func ParseOrphanPolicy(value string) (OrphanPolicy, error) {
+	switch policy := OrphanPolicy(value); policy {
+	case OrphanPolicyReport, OrphanPolicyDelete:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown orphan policy %q, expected %s or %s", value, OrphanPolicyReport, OrphanPolicyDelete)
+}
+
+// OrphanSweeper finds generated Ingresses and HTTPRoutes whose AppIngress or ClusterAppIngress no longer exists, e.g.
+// because its finalizer was removed by hand or the controller was not running when it was deleted.
+// It sweeps once on startup and then periodically.
+type OrphanSweeper struct {
+	client.Client
+	Recorder record.EventRecorder
+
+	// Policy decides whether orphaned Ingresses are deleted or only reported
+	Policy OrphanPolicy
+
+	// Interval is the time between two sweeps
+	Interval time.Duration
+
+	// GatewayAPI makes the sweeper check the generated HTTPRoutes as well
+	GatewayAPI bool
+}
+
+var _ manager.LeaderElectionRunnable = &OrphanSweeper{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, so only the leader deletes orphans
+func (s *OrphanSweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable and sweeps until the context is cancelled
+func (s *OrphanSweeper) Start(ctx context.Context) error {
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithName("orphan-sweeper"))
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.Sweep(ctx); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to sweep orphaned objects")
+		}
+	}, s.Interval)
+	return nil
+}
+
+// Sweep checks every managed Ingress, and every managed HTTPRoute when the Gateway API is enabled,
+// once and reports or deletes the orphaned ones. The orphan counts of a kind are only updated when
+// every object of that kind could be checked.
+func (s *OrphanSweeper) Sweep(ctx context.Context) error {
+	ingresses := &networkingv1.IngressList{}
+	if err := s.List(ctx, ingresses,
+		client.MatchingLabels{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue}); err != nil {
+		return err
+	}
+	objects := make([]client.Object, 0, len(ingresses.Items))
+	for i := range ingresses.Items {
+		objects = append(objects, &ingresses.Items[i])
+	}
+	if err := s.sweep(ctx, "Ingress", objects); err != nil {
+		return err
+	}
+	if !s.GatewayAPI {
+		return nil
+	}
+
+	routes := &gatewayv1.HTTPRouteList{}
+	if err := s.List(ctx, routes,
+		client.MatchingLabels{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue}); err != nil {
+		return err
+	}
+	objects = make([]client.Object, 0, len(routes.Items))
+	for i := range routes.Items {
+		objects = append(objects, &routes.Items[i])
+	}
+	return s.sweep(ctx, "HTTPRoute", objects)
+}
+
+// sweep reports or deletes the orphaned objects of the kind
+func (s *OrphanSweeper) sweep(ctx context.Context, kind string, objects []client.Object) error {
+	counts := map[string]int{sourceref.ReasonSourceNotFound: 0, sourceref.ReasonSourceRecreated: 0}
+	for _, obj := range objects {
+		reason, err := sourceref.OrphanReason(ctx, s, obj)
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			continue
+		}
+		counts[reason]++
+
+		source := obj.GetAnnotations()[ingressv1alpha1.SourceAnnotation]
+		if s.Policy != OrphanPolicyDelete {
+			log.FromContext(ctx).Info("Found orphaned "+kind, "kind", kind, "name", client.ObjectKeyFromObject(obj),
+				"source", source, "reason", reason)
+			continue
+		}
+		uid := obj.GetUID()
+		if err := s.Delete(ctx, obj, client.Preconditions{UID: &uid}); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+		orphanedIngressesDeleted.WithLabelValues(kind).Inc()
+		log.FromContext(ctx).Info("Deleted orphaned "+kind, "kind", kind, "name", client.ObjectKeyFromObject(obj),
+			"source", source, "reason", reason)
+		s.Recorder.Eventf(obj, corev1.EventTypeNormal, "OrphanDeleted", "Deleted, %s no longer generates it",
+			describeSource(source))
+	}
+
+	for reason, count := range counts {
+		orphanedIngresses.WithLabelValues(kind, reason).Set(float64(count))
+	}
+	return nil
+}
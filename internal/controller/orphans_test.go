@@ -0,0 +1,187 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/sourceref"
+)
+
+var _ = Describe("OrphanSweeper", func() {
+	const namespace = "default"
+
+	var (
+		ctx      = context.Background()
+		orphan   *networkingv1.Ingress
+		kept     *networkingv1.Ingress
+		existing *ingressv1alpha1.AppIngress
+	)
+
+	managedIngress := func(name, source string) *networkingv1.Ingress {
+		ingress := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Labels:      map[string]string{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue},
+				Annotations: map[string]string{ingressv1alpha1.SourceAnnotation: source},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{Host: name + ".example.com"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, ingress)).To(Succeed())
+		return ingress
+	}
+
+	BeforeEach(func() {
+		existing = &ingressv1alpha1.AppIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "sweeper-source", Namespace: namespace},
+			Spec: ingressv1alpha1.AppIngressSpec{
+				Template: ingressv1alpha1.IngressTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "sweeper-kept"},
+				},
+				TargetNamespace: namespace,
+			},
+		}
+		Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+		kept = managedIngress("sweeper-kept", namespace+"/"+existing.Name)
+		orphan = managedIngress("sweeper-orphan", namespace+"/deleted-appingress")
+	})
+
+	AfterEach(func() {
+		for _, obj := range []client.Object{orphan, kept, existing} {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, obj))).To(Succeed())
+		}
+	})
+
+	It("should only report orphaned Ingresses by default", func() {
+		sweeper := &OrphanSweeper{
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(100),
+			Policy:   OrphanPolicyReport,
+			Interval: time.Minute,
+		}
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+
+		Expect(testutil.ToFloat64(orphanedIngresses.WithLabelValues("Ingress", sourceref.ReasonSourceNotFound))).To(BeNumerically(">=", 1))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(orphan), &networkingv1.Ingress{})).To(Succeed())
+	})
+
+	It("should delete orphaned Ingresses when asked to", func() {
+		sweeper := &OrphanSweeper{
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(100),
+			Policy:   OrphanPolicyDelete,
+			Interval: time.Minute,
+		}
+		deleted := testutil.ToFloat64(orphanedIngressesDeleted.WithLabelValues("Ingress"))
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+
+		Expect(testutil.ToFloat64(orphanedIngressesDeleted.WithLabelValues("Ingress"))).To(BeNumerically(">=", deleted+1))
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(orphan), &networkingv1.Ingress{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(kept), &networkingv1.Ingress{})).To(Succeed())
+	})
+
+	It("should sweep orphaned HTTPRoutes when the Gateway API is enabled", func() {
+		route := &gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "sweeper-orphan-route",
+				Namespace:   namespace,
+				Labels:      map[string]string{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue},
+				Annotations: map[string]string{ingressv1alpha1.SourceAnnotation: namespace + "/deleted-appingress"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, route))).To(Succeed())
+		})
+		sweeper := &OrphanSweeper{
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(100),
+			Policy:   OrphanPolicyDelete,
+			Interval: time.Minute,
+		}
+
+		By("leaving HTTPRoutes alone while the Gateway API is disabled")
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), &gatewayv1.HTTPRoute{})).To(Succeed())
+
+		By("deleting them once it is enabled")
+		sweeper.GatewayAPI = true
+		deleted := testutil.ToFloat64(orphanedIngressesDeleted.WithLabelValues("HTTPRoute"))
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+		Expect(testutil.ToFloat64(orphanedIngressesDeleted.WithLabelValues("HTTPRoute"))).To(Equal(deleted + 1))
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(route), &gatewayv1.HTTPRoute{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should leave Ingresses a recreated source targets to the reconciler", func() {
+		sweeper := &OrphanSweeper{
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(100),
+			Policy:   OrphanPolicyDelete,
+			Interval: time.Minute,
+		}
+		// Both Ingresses were generated by an earlier source with the same name
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(kept), kept)).To(Succeed())
+		kept.Annotations[ingressv1alpha1.SourceUIDAnnotation] = "previous-uid"
+		Expect(k8sClient.Update(ctx, kept)).To(Succeed())
+		renamed := managedIngress("sweeper-renamed", namespace+"/"+existing.Name)
+		renamed.Annotations[ingressv1alpha1.SourceUIDAnnotation] = "previous-uid"
+		Expect(k8sClient.Update(ctx, renamed)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, renamed))).To(Succeed())
+		})
+
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(kept), &networkingv1.Ingress{})).To(Succeed())
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(renamed), &networkingv1.Ingress{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("keeping Ingresses in namespaces the source selects by labels")
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(existing), existing)).To(Succeed())
+		existing.Spec.TargetNamespace = ""
+		existing.Spec.NamespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{corev1.LabelMetadataName: namespace},
+		}
+		Expect(k8sClient.Update(ctx, existing)).To(Succeed())
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(kept), &networkingv1.Ingress{})).To(Succeed())
+
+		By("deleting them once the source no longer targets the namespace")
+		existing.Spec.NamespaceSelector.MatchLabels[corev1.LabelMetadataName] = "elsewhere"
+		Expect(k8sClient.Update(ctx, existing)).To(Succeed())
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(kept), &networkingv1.Ingress{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
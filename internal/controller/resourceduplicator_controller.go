@@ -0,0 +1,741 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/policy"
+)
+
+// DefaultDuplicatorKinds are the kinds ResourceDuplicators may create unless configured otherwise
+var DefaultDuplicatorKinds = []string{"v1/ConfigMap", "networking.k8s.io/v1/NetworkPolicy"}
+
+// ParseKinds parses kinds in the "<apiVersion>/<kind>" form, e.g. "v1/ConfigMap" or
+// "networking.k8s.io/v1/NetworkPolicy"
+func ParseKinds(values []string) ([]schema.GroupVersionKind, error) {
+	kinds := make([]schema.GroupVersionKind, 0, len(values))
+	for _, value := range values {
+		apiVersion, kind, ok := cutLast(value, "/")
+		if !ok || apiVersion == "" || kind == "" {
+			return nil, fmt.Errorf("invalid kind %q, expected <apiVersion>/<kind>", value)
+		}
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kind %q: %w", value, err)
+		}
+		kinds = append(kinds, gv.WithKind(kind))
+	}
+	return kinds, nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (string, string, bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// kindString formats the kind in the form accepted by ParseKinds
+func kindString(gvk schema.GroupVersionKind) string {
+	return gvk.GroupVersion().String() + "/" + gvk.Kind
+}
+
+// ResourceDuplicatorReconciler reconciles a ResourceDuplicator object
+type ResourceDuplicatorReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// AllowedKinds are the kinds ResourceDuplicators may create. Templates of any other kind are
+	// rejected.
+	AllowedKinds []schema.GroupVersionKind
+
+	// RequireConsent limits the target namespaces to those that accept objects from the namespace
+	// of the ResourceDuplicator through the accept-from label or annotation
+	RequireConsent bool
+
+	// Policy restricts the target namespaces according to the AppIngressPolicies. ResourceDuplicators
+	// may target any namespace that is not denied when it is nil.
+	Policy *policy.Evaluator
+
+	// DeniedNamespaces lists namespaces ResourceDuplicators may never write into
+	DeniedNamespaces []string
+
+	// The kinds are only known once a ResourceDuplicator uses them, so their watches are added to
+	// the running controller on demand
+	controller controller.Controller
+	cache      ctrlcache.Cache
+	watchMu    sync.Mutex
+	watched    map[schema.GroupVersionKind]bool
+}
+
+// +kubebuilder:rbac:groups=ingress.example.com,resources=resourceduplicators,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ingress.example.com,resources=resourceduplicators/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ingress.example.com,resources=resourceduplicators/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+
+// Condition Types for ResourceDuplicator, besides NamespaceValid, PolicyAllowed and Ready
+const (
+	ConditionTypeTemplateValid    = "TemplateValid"
+	ConditionTypeResourcesCreated = "ResourcesCreated"
+	ConditionTypeResourceConflict = "ResourceConflict"
+)
+
+// duplicatorTemplateNameIndexKey indexes ResourceDuplicators by the name of the object they create
+const duplicatorTemplateNameIndexKey = ".spec.template.metadata.name"
+
+// Reconcile handles the reconciliation loop for ResourceDuplicator resources
+func (r *ResourceDuplicatorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Reconciling ResourceDuplicator")
+
+	duplicator := &ingressv1alpha1.ResourceDuplicator{}
+	if err := r.Get(ctx, req.NamespacedName, duplicator); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	template, templateErr := r.parseTemplate(duplicator)
+
+	// Handle deletion
+	if !duplicator.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(duplicator, finalizerName) {
+			logger.Info("Cleaning up duplicated objects", "count", len(duplicator.Status.Resources))
+		}
+		// Delete every recorded object together with the ones currently described by the spec,
+		// which covers objects created before they were recorded in status
+		return ctrl.Result{}, finalize(ctx, r.Client, r.Recorder, duplicator, cleanupStep{
+			what: "duplicated object",
+			cleanup: func() error {
+				refs := slices.Clone(duplicator.Status.Resources)
+				if templateErr == nil {
+					for _, namespace := range duplicator.Spec.ExplicitTargetNamespaces() {
+						refs = appendTypedRef(refs, typedRef(template, namespace))
+					}
+				}
+				_, err := r.cleanupResources(ctx, duplicator, refs, nil)
+				return err
+			},
+		})
+	}
+
+	if err := ensureFinalizer(ctx, r.Client, duplicator); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Objects created from the last valid template are kept until the template is fixed. A kind that
+	// is not installed yet is retried, anything else waits for the template to change.
+	if templateErr != nil {
+		var invalid *syncError
+		if !errors.As(templateErr, &invalid) && !meta.IsNoMatchError(templateErr) {
+			return ctrl.Result{}, templateErr
+		}
+		reason := "KindNotFound"
+		if invalid != nil {
+			reason = invalid.reason
+		}
+		logger.Info("Invalid template", "reason", reason, "error", templateErr.Error())
+		if setDuplicatorCondition(duplicator, metav1.Condition{
+			Type:    ConditionTypeTemplateValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  reason,
+			Message: templateErr.Error(),
+		}) {
+			r.Recorder.Event(duplicator, corev1.EventTypeWarning, "InvalidTemplate", templateErr.Error())
+		}
+		setDuplicatorReadyCondition(duplicator)
+		duplicator.Status.ObservedGeneration = duplicator.Generation
+		if err := r.Status().Update(ctx, duplicator); err != nil {
+			logger.Error(err, "Failed to update ResourceDuplicator status")
+			return ctrl.Result{}, err
+		}
+		if invalid == nil {
+			return ctrl.Result{}, templateErr
+		}
+		return ctrl.Result{}, nil
+	}
+	setDuplicatorCondition(duplicator, metav1.Condition{
+		Type:    ConditionTypeTemplateValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Valid",
+		Message: fmt.Sprintf("Template is a valid %s", kindString(template.GroupVersionKind())),
+	})
+	if err := r.watchKind(template.GroupVersionKind()); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Skip target namespaces the ResourceDuplicator may not write into
+	source := duplicatorSource(duplicator)
+	gate := targetGate{
+		requireConsent: r.RequireConsent,
+		denials: func(ctx context.Context, namespaces []string) (map[string]string, error) {
+			return r.targetDenials(ctx, duplicator, namespaces)
+		},
+	}
+	if r.Policy == nil {
+		gate.notEnforced = "AppIngressPolicies are not enforced"
+	}
+	resolved, err := resolveTargets(ctx, r.Client, r.Recorder, source, gate)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if resolved == nil {
+		setDuplicatorReadyCondition(duplicator)
+		duplicator.Status.ObservedGeneration = duplicator.Generation
+		if err := r.Status().Update(ctx, duplicator); err != nil {
+			logger.Error(err, "Failed to update ResourceDuplicator status")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	namespaces := resolved.allowed
+
+	// Create/Update the object in every target namespace
+	var keep []ingressv1alpha1.TypedResourceReference
+	var conflicts []*ingressConflictError
+	var errs []error
+	var targets []ingressv1alpha1.TargetStatus
+	for _, namespace := range namespaces {
+		ref := typedRef(template, namespace)
+		target := ingressv1alpha1.TargetStatus{Namespace: namespace}
+		obj, result, err := r.reconcileResource(ctx, duplicator, template, namespace)
+		var conflictErr *ingressConflictError
+		if errors.As(err, &conflictErr) {
+			// Leave the existing object untouched, the watch requeues once it changes
+			logger.Info("Duplicated object is not managed by this ResourceDuplicator", "object", ref,
+				"reason", conflictErr.reason)
+			r.Recorder.Event(duplicator, corev1.EventTypeWarning, "ResourceConflict", conflictErr.Error())
+			conflicts = append(conflicts, conflictErr)
+			target.Status, target.Reason, target.Message = metav1.ConditionFalse, conflictErr.reason, conflictErr.Error()
+		} else if err != nil {
+			logger.Error(err, "Failed to create/update duplicated object", "object", ref)
+			// The object may exist even though the call failed, keep track of it
+			keep = append(keep, ref)
+			errs = append(errs, err)
+			target.Status, target.Reason, target.Message = metav1.ConditionFalse, "Error",
+				fmt.Sprintf("Failed to create/update %s: %v", ref.Kind, err)
+		} else {
+			keep = append(keep, ref)
+			target.Status, target.Reason, target.Message = metav1.ConditionTrue, "Created",
+				fmt.Sprintf("%s created/updated successfully", ref.Kind)
+			r.recordResourceResult(duplicator, obj, result)
+		}
+		targets = append(targets, target)
+	}
+	targets = append(targets, resolved.skipped(source)...)
+	setOutputConditions(source, ConditionTypeResourcesCreated, ConditionTypeResourceConflict, template.GetKind(),
+		"ResourceDuplicator", resolved.countOutcomes(len(namespaces)), conflicts, errs)
+	setDuplicatorReadyCondition(duplicator)
+
+	// Remove objects that are no longer desired, e.g. after a rename, a kind change or when a
+	// namespace stops matching the selector
+	remaining, cleanupErr := pruneStale(ctx, r.Recorder, duplicator, "duplicated objects", duplicator.Status.Resources,
+		keep, func(refs, keep []ingressv1alpha1.TypedResourceReference) ([]ingressv1alpha1.TypedResourceReference, error) {
+			return r.cleanupResources(ctx, duplicator, refs, keep)
+		}, compareTypedRefs)
+	if cleanupErr != nil {
+		errs = append(errs, cleanupErr)
+	}
+	slices.SortFunc(targets, func(a, b ingressv1alpha1.TargetStatus) int {
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
+	duplicator.Status.Resources = remaining
+	duplicator.Status.Targets = targets
+	duplicator.Status.TargetCount = int32(len(targets))
+	duplicator.Status.ObservedGeneration = duplicator.Generation
+
+	if err := r.Status().Update(ctx, duplicator); err != nil {
+		logger.Error(err, "Failed to update ResourceDuplicator status")
+		return ctrl.Result{}, err
+	}
+	if len(errs) > 0 {
+		return ctrl.Result{}, kerrors.NewAggregate(errs)
+	}
+
+	logger.Info("Reconciliation completed successfully")
+	return ctrl.Result{}, nil
+}
+
+// parseTemplate decodes the template of the ResourceDuplicator. Templates that cannot be created
+// are reported as a *syncError; a kind the API server does not know yields a no-match error.
+func (r *ResourceDuplicatorReconciler) parseTemplate(
+	duplicator *ingressv1alpha1.ResourceDuplicator) (*unstructured.Unstructured, error) {
+	template := &unstructured.Unstructured{}
+	if err := template.UnmarshalJSON(duplicator.Spec.Template.Raw); err != nil {
+		return nil, &syncError{reason: "InvalidTemplate", message: "Template cannot be decoded: " + err.Error()}
+	}
+	if template.GetName() == "" {
+		return nil, &syncError{reason: "InvalidTemplate", message: "Template must set metadata.name"}
+	}
+	for key := range template.GetAnnotations() {
+		if slices.Contains(ingressv1alpha1.MarkerAnnotations, key) {
+			return nil, &syncError{
+				reason:  "InvalidTemplate",
+				message: fmt.Sprintf("Template may not set the %s annotation, it is set by the controller", key),
+			}
+		}
+	}
+	gvk := template.GroupVersionKind()
+	if !slices.Contains(r.AllowedKinds, gvk) {
+		allowed := make([]string, 0, len(r.AllowedKinds))
+		for _, kind := range r.AllowedKinds {
+			allowed = append(allowed, kindString(kind))
+		}
+		return nil, &syncError{
+			reason: "KindNotAllowed",
+			message: fmt.Sprintf("Kind %s is not allowed, the controller allows: %s", kindString(gvk),
+				strings.Join(allowed, ", ")),
+		}
+	}
+	mapping, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return nil, &syncError{
+			reason:  "ClusterScoped",
+			message: fmt.Sprintf("Kind %s is cluster-scoped and cannot be duplicated into namespaces", kindString(gvk)),
+		}
+	}
+	return template, nil
+}
+
+// watchKind watches objects of the kind so drift is reverted and deleted objects are recreated.
+// Watches are only added once per kind, and not at all when the reconciler runs without a controller.
+func (r *ResourceDuplicatorReconciler) watchKind(gvk schema.GroupVersionKind) error {
+	if r.controller == nil {
+		return nil
+	}
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+	if r.watched[gvk] {
+		return nil
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := r.controller.Watch(source.Kind[client.Object](r.cache, obj,
+		handler.EnqueueRequestsFromMapFunc(r.findDuplicatorsForResource))); err != nil {
+		return err
+	}
+	if r.watched == nil {
+		r.watched = make(map[schema.GroupVersionKind]bool)
+	}
+	r.watched[gvk] = true
+	return nil
+}
+
+// reconcileResource applies the template in a single target namespace and returns the applied
+// object together with the operation that was performed. Like the Ingresses of an AppIngress, the
+// object is tracked through labels and annotations and applied with server-side apply, so fields
+// added by other controllers are preserved.
+func (r *ResourceDuplicatorReconciler) reconcileResource(ctx context.Context,
+	duplicator *ingressv1alpha1.ResourceDuplicator, template *unstructured.Unstructured,
+	namespace string) (*unstructured.Unstructured, controllerutil.OperationResult, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(template.GroupVersionKind())
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: template.GetName()}, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return existing, controllerutil.OperationResultNone, err
+		}
+	}
+	if err := checkDuplicatorOwnership(duplicator, template.GetKind(), existing); err != nil {
+		return existing, controllerutil.OperationResultNone, err
+	}
+
+	obj := duplicatedObject(duplicator, template, namespace)
+	opts := []client.PatchOption{client.FieldOwner(fieldManager)}
+	if duplicator.Spec.FieldConflictPolicy != ingressv1alpha1.FieldConflictPolicyAbort {
+		opts = append(opts, client.ForceOwnership)
+	}
+	if err := r.Patch(ctx, obj, client.Apply, opts...); err != nil {
+		if apierrors.IsConflict(err) {
+			return existing, controllerutil.OperationResultNone, &ingressConflictError{
+				reason: "FieldConflict",
+				message: fmt.Sprintf("%s %s/%s has fields managed by someone else, "+
+					"set fieldConflictPolicy to Force to take them over: %v", obj.GetKind(), namespace, obj.GetName(), err),
+			}
+		}
+		return existing, controllerutil.OperationResultNone, err
+	}
+
+	switch {
+	case existing.GetResourceVersion() == "":
+		return obj, controllerutil.OperationResultCreated, nil
+	case existing.GetResourceVersion() != obj.GetResourceVersion():
+		return obj, controllerutil.OperationResultUpdated, nil
+	}
+	return obj, controllerutil.OperationResultNone, nil
+}
+
+// duplicatedObject returns the template placed in the target namespace and marked with the
+// tracking label and annotation. Only the name, labels and annotations of the template metadata are
+// applied, and the status is left to the object's own controller.
+func duplicatedObject(duplicator *ingressv1alpha1.ResourceDuplicator, template *unstructured.Unstructured,
+	namespace string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: make(map[string]any, len(template.Object))}
+	for key, value := range template.Object {
+		if key != "metadata" && key != "status" {
+			obj.Object[key] = runtime.DeepCopyJSONValue(value)
+		}
+	}
+	obj.SetName(template.GetName())
+	obj.SetNamespace(namespace)
+
+	labels := make(map[string]string, len(template.GetLabels())+1)
+	for k, v := range template.GetLabels() {
+		labels[k] = v
+	}
+	labels[ingressv1alpha1.ManagedByLabel] = ingressv1alpha1.ManagedByValue
+	obj.SetLabels(labels)
+	annotations := make(map[string]string, len(template.GetAnnotations())+1)
+	for k, v := range template.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[ingressv1alpha1.DuplicatorAnnotation] = duplicatorKey(duplicator)
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+// checkDuplicatorOwnership verifies that the existing object may be written according to the
+// adoption policy of the ResourceDuplicator. Objects that do not exist yet are always writable.
+// Objects generated from an AppIngress are never taken over, and objects outside the namespace of
+// the ResourceDuplicator are never adopted, since whoever may create a ResourceDuplicator could
+// otherwise overwrite objects in namespaces they have no access to.
+func checkDuplicatorOwnership(duplicator *ingressv1alpha1.ResourceDuplicator, kind string, obj client.Object) error {
+	if obj.GetResourceVersion() == "" || ingressv1alpha1.IsDuplicatedBy(obj, duplicatorKey(duplicator)) {
+		return nil
+	}
+
+	policy := duplicator.Spec.AdoptionPolicy
+	if policy != ingressv1alpha1.AdoptionPolicyNever && policy != "" && obj.GetNamespace() != duplicator.Namespace {
+		return &ingressConflictError{
+			reason: "AdoptionNotAllowed",
+			message: fmt.Sprintf("%s %s/%s already exists, ResourceDuplicators only adopt objects in their own namespace",
+				kind, obj.GetNamespace(), obj.GetName()),
+		}
+	}
+	if owner := managingObject(obj); owner != "" {
+		if policy == ingressv1alpha1.AdoptionPolicyAlways && !hasSourceMarkers(obj) {
+			return nil
+		}
+		return &ingressConflictError{
+			reason:  "OwnedByOther",
+			message: fmt.Sprintf("%s %s/%s is managed by %s", kind, obj.GetNamespace(), obj.GetName(), owner),
+		}
+	}
+	if hasSourceMarkers(obj) {
+		return &ingressConflictError{
+			reason:  "OwnedByOther",
+			message: fmt.Sprintf("%s %s/%s was generated from an AppIngress", kind, obj.GetNamespace(), obj.GetName()),
+		}
+	}
+	if policy == ingressv1alpha1.AdoptionPolicyIfUnowned || policy == ingressv1alpha1.AdoptionPolicyAlways {
+		return nil
+	}
+	return &ingressConflictError{
+		reason: "NotManaged",
+		message: fmt.Sprintf("%s %s/%s already exists and is not managed by the controller, "+
+			"set adoptionPolicy to adopt it", kind, obj.GetNamespace(), obj.GetName()),
+	}
+}
+
+// hasSourceMarkers reports whether the object carries the annotations of an object generated from an
+// AppIngress or ClusterAppIngress, whether or not it is still labelled as managed
+func hasSourceMarkers(obj client.Object) bool {
+	annotations := obj.GetAnnotations()
+	return annotations[ingressv1alpha1.SourceAnnotation] != "" || annotations[ingressv1alpha1.SourceUIDAnnotation] != ""
+}
+
+// managingObject describes the AppIngress or ResourceDuplicator managing the object, or returns an
+// empty string when the object is not managed by the controller
+func managingObject(obj client.Object) string {
+	switch {
+	case ingressv1alpha1.IsDuplicated(obj):
+		return "ResourceDuplicator " + obj.GetAnnotations()[ingressv1alpha1.DuplicatorAnnotation]
+	case ingressv1alpha1.IsManaged(obj):
+		return describeSource(obj.GetAnnotations()[ingressv1alpha1.SourceAnnotation])
+	}
+	return ""
+}
+
+// recordResourceResult emits an event on the ResourceDuplicator when an object was created or
+// updated. Unchanged objects are not reported.
+func (r *ResourceDuplicatorReconciler) recordResourceResult(duplicator *ingressv1alpha1.ResourceDuplicator,
+	obj client.Object, result controllerutil.OperationResult) {
+	var verb string
+	switch result {
+	case controllerutil.OperationResultCreated:
+		verb = "Created"
+	case controllerutil.OperationResultUpdated:
+		verb = "Updated"
+	default:
+		return
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	r.Recorder.Eventf(duplicator, corev1.EventTypeNormal, "Resource"+verb, "%s %s %s/%s",
+		verb, kind, obj.GetNamespace(), obj.GetName())
+}
+
+// setDuplicatorReadyCondition summarizes the other conditions into the Ready condition. The
+// ResourceDuplicator is ready once its template is valid, every target namespace exists and the
+// object is in place in all of them. Otherwise the reason and message of the first unhealthy
+// condition are surfaced.
+func setDuplicatorReadyCondition(duplicator *ingressv1alpha1.ResourceDuplicator) {
+	condition, _ := summarizeReady(duplicatorSource(duplicator), metav1.Condition{
+		Type:    ConditionTypeReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Created",
+		Message: "Object is in place in every target namespace",
+	},
+		readyCheck{ConditionTypeTemplateValid, metav1.ConditionTrue},
+		readyCheck{ConditionTypeNamespaceValid, metav1.ConditionTrue},
+		readyCheck{ConditionTypePolicyAllowed, metav1.ConditionTrue},
+		readyCheck{ConditionTypeResourceConflict, metav1.ConditionFalse},
+		readyCheck{ConditionTypeResourcesCreated, metav1.ConditionTrue},
+	)
+	setDuplicatorCondition(duplicator, condition)
+}
+
+// targetDenials returns a message for every target namespace the ResourceDuplicator may not write
+// into: the denied namespaces and, when policies are enforced, the namespaces no AppIngressPolicy
+// allows for the namespace of the ResourceDuplicator
+func (r *ResourceDuplicatorReconciler) targetDenials(ctx context.Context,
+	duplicator *ingressv1alpha1.ResourceDuplicator, namespaces []string) (map[string]string, error) {
+	denials := map[string]string{}
+	if r.Policy != nil {
+		policyDenials, err := r.Policy.NamespaceDenials(ctx, duplicator.Namespace, namespaces)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(denials, policyDenials)
+	}
+	addDeniedNamespaces(denials, r.DeniedNamespaces, namespaces, "a ResourceDuplicator")
+	return denials, nil
+}
+
+// setDuplicatorCondition sets the condition on the ResourceDuplicator, stamped with the generation
+// it was computed from. It reports whether the condition changed.
+func setDuplicatorCondition(duplicator *ingressv1alpha1.ResourceDuplicator, condition metav1.Condition) bool {
+	return duplicatorSource(duplicator).setCondition(condition)
+}
+
+// duplicatorKey returns the value of the duplicator annotation for objects created from the
+// ResourceDuplicator
+func duplicatorKey(duplicator *ingressv1alpha1.ResourceDuplicator) string {
+	return duplicator.Namespace + "/" + duplicator.Name
+}
+
+// typedRef returns the reference of the object created from the template in the target namespace
+func typedRef(template *unstructured.Unstructured, namespace string) ingressv1alpha1.TypedResourceReference {
+	return ingressv1alpha1.TypedResourceReference{
+		APIVersion: template.GetAPIVersion(),
+		Kind:       template.GetKind(),
+		Namespace:  namespace,
+		Name:       template.GetName(),
+	}
+}
+
+// compareTypedRefs orders references by namespace, name, apiVersion and kind
+func compareTypedRefs(a, b ingressv1alpha1.TypedResourceReference) int {
+	if c := compareRefs(ingressv1alpha1.ResourceReference{Namespace: a.Namespace, Name: a.Name},
+		ingressv1alpha1.ResourceReference{Namespace: b.Namespace, Name: b.Name}); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.APIVersion, b.APIVersion); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Kind, b.Kind)
+}
+
+// appendTypedRef adds ref to refs unless it is already present
+func appendTypedRef(refs []ingressv1alpha1.TypedResourceReference,
+	ref ingressv1alpha1.TypedResourceReference) []ingressv1alpha1.TypedResourceReference {
+	if slices.Contains(refs, ref) {
+		return refs
+	}
+	return append(refs, ref)
+}
+
+// cleanupResources deletes the referenced objects except the ones in keep. It returns the
+// references that could not be deleted so they can be retried.
+func (r *ResourceDuplicatorReconciler) cleanupResources(ctx context.Context,
+	duplicator *ingressv1alpha1.ResourceDuplicator,
+	refs, keep []ingressv1alpha1.TypedResourceReference) ([]ingressv1alpha1.TypedResourceReference, error) {
+	return deleteUnkept(refs, keep, func(ref ingressv1alpha1.TypedResourceReference) error {
+		return r.deleteResource(ctx, duplicator, ref)
+	})
+}
+
+// deleteResource deletes the referenced object if it is still managed by the ResourceDuplicator.
+// Objects that are gone, whose kind is no longer served, or that were taken over by someone else
+// are left alone.
+func (r *ResourceDuplicatorReconciler) deleteResource(ctx context.Context,
+	duplicator *ingressv1alpha1.ResourceDuplicator, ref ingressv1alpha1.TypedResourceReference) error {
+	logger := log.FromContext(ctx)
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(ref.APIVersion)
+	obj.SetKind(ref.Kind)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, obj); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			logger.Info("Duplicated object already deleted or not found", "object", ref)
+			return nil
+		}
+		return err
+	}
+	if !ingressv1alpha1.IsDuplicatedBy(obj, duplicatorKey(duplicator)) {
+		logger.Info("Skipping deletion of object not managed by this ResourceDuplicator", "object", ref)
+		return nil
+	}
+
+	// The UID precondition protects an object recreated by someone else in the meantime
+	uid := obj.GetUID()
+	if err := r.Delete(ctx, obj, client.Preconditions{UID: &uid}); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	logger.Info("Deleted duplicated object", "object", ref)
+	r.Recorder.Eventf(duplicator, corev1.EventTypeNormal, "ResourceDeleted", "Deleted %s %s/%s",
+		ref.Kind, ref.Namespace, ref.Name)
+	return nil
+}
+
+// duplicatorTargetsNamespace reports whether the namespace is listed in the ResourceDuplicator spec
+// or status
+func duplicatorTargetsNamespace(duplicator *ingressv1alpha1.ResourceDuplicator, namespace string) bool {
+	if slices.Contains(duplicator.Spec.ExplicitTargetNamespaces(), namespace) {
+		return true
+	}
+	return slices.ContainsFunc(duplicator.Status.Targets, func(target ingressv1alpha1.TargetStatus) bool {
+		return target.Namespace == namespace
+	})
+}
+
+// findDuplicatorsForResource maps a duplicated object back to the ResourceDuplicator it was created
+// from. ResourceDuplicators that target the same name but are blocked by an ownership conflict are
+// included as well, so they can take over once the object is released.
+func (r *ResourceDuplicatorReconciler) findDuplicatorsForResource(ctx context.Context,
+	obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	if source := obj.GetAnnotations()[ingressv1alpha1.DuplicatorAnnotation]; ingressv1alpha1.IsDuplicated(obj) {
+		namespace, name, err := cache.SplitMetaNamespaceKey(source)
+		if err != nil || namespace == "" || name == "" {
+			log.FromContext(ctx).Info("Ignoring object with malformed duplicator annotation",
+				"object", client.ObjectKeyFromObject(obj), "source", source)
+		} else {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: namespace, Name: name},
+			})
+		}
+	}
+
+	duplicators := &ingressv1alpha1.ResourceDuplicatorList{}
+	if err := r.List(ctx, duplicators, client.MatchingFields{duplicatorTemplateNameIndexKey: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list ResourceDuplicators for object",
+			"object", client.ObjectKeyFromObject(obj))
+		return requests
+	}
+	for _, duplicator := range duplicators.Items {
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&duplicator)}
+		if duplicatorTargetsNamespace(&duplicator, obj.GetNamespace()) && !slices.Contains(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// findDuplicatorsForNamespace maps a Namespace to the ResourceDuplicators targeting it by name or
+// by selector, or inside it. Both the old and the new object of an update are mapped, so namespaces
+// that stop matching are cleaned up.
+func (r *ResourceDuplicatorReconciler) findDuplicatorsForNamespace(ctx context.Context,
+	obj client.Object) []reconcile.Request {
+	duplicators := &ingressv1alpha1.ResourceDuplicatorList{}
+	if err := r.List(ctx, duplicators); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list ResourceDuplicators for namespace", "namespace", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, duplicator := range duplicators.Items {
+		matches := duplicator.Namespace == obj.GetName() || duplicatorTargetsNamespace(&duplicator, obj.GetName())
+		if !matches && duplicator.Spec.NamespaceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(duplicator.Spec.NamespaceSelector)
+			matches = err == nil && selector.Matches(labels.Set(obj.GetLabels()))
+		}
+		if matches {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&duplicator)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ResourceDuplicatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &ingressv1alpha1.ResourceDuplicator{},
+		duplicatorTemplateNameIndexKey, func(obj client.Object) []string {
+			template := &unstructured.Unstructured{}
+			if err := template.UnmarshalJSON(obj.(*ingressv1alpha1.ResourceDuplicator).Spec.Template.Raw); err != nil {
+				return nil
+			}
+			return []string{template.GetName()}
+		}); err != nil {
+		return err
+	}
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&ingressv1alpha1.ResourceDuplicator{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.findDuplicatorsForNamespace)).
+		Named("resourceduplicator").
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller, r.cache = c, mgr.GetCache()
+	return nil
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+var _ = Describe("ResourceDuplicator Controller", Ordered, func() {
+	const (
+		resourceName = "test-duplicator"
+		namespace    = "default"
+		targetNs     = "test-duplicator-target"
+	)
+
+	var (
+		ctx                  = context.Background()
+		namespacedName       = types.NamespacedName{Name: resourceName, Namespace: namespace}
+		duplicator           *ingressv1alpha1.ResourceDuplicator
+		controllerReconciler *ResourceDuplicatorReconciler
+	)
+
+	BeforeAll(func() {
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: targetNs},
+		})).To(Succeed())
+	})
+
+	BeforeEach(func() {
+		allowedKinds, err := ParseKinds(DefaultDuplicatorKinds)
+		Expect(err).NotTo(HaveOccurred())
+		controllerReconciler = &ResourceDuplicatorReconciler{
+			Client:       k8sClient,
+			Scheme:       k8sClient.Scheme(),
+			Recorder:     record.NewFakeRecorder(100),
+			AllowedKinds: allowedKinds,
+		}
+	})
+
+	AfterEach(func() {
+		if duplicator != nil {
+			_ = k8sClient.Delete(ctx, duplicator)
+			_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			duplicator = nil
+		}
+	})
+
+	createDuplicator := func(template string, adoptionPolicy ...ingressv1alpha1.AdoptionPolicy) {
+		duplicator = &ingressv1alpha1.ResourceDuplicator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      resourceName,
+				Namespace: namespace,
+			},
+			Spec: ingressv1alpha1.ResourceDuplicatorSpec{
+				Template:        runtime.RawExtension{Raw: []byte(template)},
+				TargetNamespace: targetNs,
+			},
+		}
+		if len(adoptionPolicy) > 0 {
+			duplicator.Spec.AdoptionPolicy = adoptionPolicy[0]
+		}
+		Expect(k8sClient.Create(ctx, duplicator)).To(Succeed())
+	}
+
+	It("should duplicate a ConfigMap and revert changes to it", func() {
+		createDuplicator(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"shared-config",` +
+			`"labels":{"team":"platform"}},"data":{"greeting":"hello"}}`)
+
+		_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		configMap := &corev1.ConfigMap{}
+		key := types.NamespacedName{Namespace: targetNs, Name: "shared-config"}
+		Expect(k8sClient.Get(ctx, key, configMap)).To(Succeed())
+		Expect(configMap.Data).To(HaveKeyWithValue("greeting", "hello"))
+		Expect(configMap.Labels).To(HaveKeyWithValue("team", "platform"))
+		Expect(configMap.Labels).To(HaveKeyWithValue(ingressv1alpha1.ManagedByLabel, ingressv1alpha1.ManagedByValue))
+		Expect(configMap.Annotations).To(HaveKeyWithValue(ingressv1alpha1.DuplicatorAnnotation,
+			namespace+"/"+resourceName))
+
+		updated := &ingressv1alpha1.ResourceDuplicator{}
+		Expect(k8sClient.Get(ctx, namespacedName, updated)).To(Succeed())
+		Expect(updated.Status.Resources).To(ConsistOf(ingressv1alpha1.TypedResourceReference{
+			APIVersion: "v1", Kind: "ConfigMap", Namespace: targetNs, Name: "shared-config",
+		}))
+		readyCondition := findCondition(updated.Status.Conditions, ConditionTypeReady)
+		Expect(readyCondition).NotTo(BeNil())
+		Expect(readyCondition.Status).To(Equal(metav1.ConditionTrue))
+
+		By("reverting a change made by someone else")
+		configMap.Data["greeting"] = "changed"
+		Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, configMap)).To(Succeed())
+		Expect(configMap.Data).To(HaveKeyWithValue("greeting", "hello"))
+
+		By("deleting the ConfigMap together with the ResourceDuplicator")
+		Expect(k8sClient.Delete(ctx, duplicator)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		duplicator = nil
+		Expect(apierrors.IsNotFound(k8sClient.Get(ctx, key, configMap))).To(BeTrue())
+	})
+
+	It("should not take over an existing object", func() {
+		existing := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "existing-config", Namespace: targetNs},
+			Data:       map[string]string{"owner": "someone-else"},
+		}
+		Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, existing) })
+		createDuplicator(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"existing-config"},` +
+			`"data":{"owner":"duplicator"}}`)
+
+		_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &ingressv1alpha1.ResourceDuplicator{}
+		Expect(k8sClient.Get(ctx, namespacedName, updated)).To(Succeed())
+		conflictCondition := findCondition(updated.Status.Conditions, ConditionTypeResourceConflict)
+		Expect(conflictCondition).NotTo(BeNil())
+		Expect(conflictCondition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(conflictCondition.Reason).To(Equal("NotManaged"))
+
+		configMap := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(existing), configMap)).To(Succeed())
+		Expect(configMap.Data).To(HaveKeyWithValue("owner", "someone-else"))
+	})
+
+	It("should not adopt objects outside its own namespace", func() {
+		existing := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "foreign-config", Namespace: targetNs},
+			Data:       map[string]string{"owner": "someone-else"},
+		}
+		Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, existing) })
+		createDuplicator(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foreign-config"},`+
+			`"data":{"owner":"duplicator"}}`, ingressv1alpha1.AdoptionPolicyIfUnowned)
+
+		_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &ingressv1alpha1.ResourceDuplicator{}
+		Expect(k8sClient.Get(ctx, namespacedName, updated)).To(Succeed())
+		conflictCondition := findCondition(updated.Status.Conditions, ConditionTypeResourceConflict)
+		Expect(conflictCondition).NotTo(BeNil())
+		Expect(conflictCondition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(conflictCondition.Reason).To(Equal("AdoptionNotAllowed"))
+
+		configMap := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(existing), configMap)).To(Succeed())
+		Expect(configMap.Data).To(HaveKeyWithValue("owner", "someone-else"))
+		Expect(configMap.Annotations).NotTo(HaveKey(ingressv1alpha1.DuplicatorAnnotation))
+	})
+
+	It("should not write into denied namespaces", func() {
+		controllerReconciler.DeniedNamespaces = []string{targetNs}
+		createDuplicator(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"denied-config"},` +
+			`"data":{"greeting":"hello"}}`)
+
+		_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &ingressv1alpha1.ResourceDuplicator{}
+		Expect(k8sClient.Get(ctx, namespacedName, updated)).To(Succeed())
+		policyCondition := findCondition(updated.Status.Conditions, ConditionTypePolicyAllowed)
+		Expect(policyCondition).NotTo(BeNil())
+		Expect(policyCondition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(policyCondition.Reason).To(Equal("Denied"))
+		readyCondition := findCondition(updated.Status.Conditions, ConditionTypeReady)
+		Expect(readyCondition).NotTo(BeNil())
+		Expect(readyCondition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(updated.Status.Resources).To(BeEmpty())
+
+		configMap := &corev1.ConfigMap{}
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: targetNs, Name: "denied-config"}, configMap)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should reject kinds that are not allowed", func() {
+		createDuplicator(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"copied-secret"}}`)
+
+		_, err := controllerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &ingressv1alpha1.ResourceDuplicator{}
+		Expect(k8sClient.Get(ctx, namespacedName, updated)).To(Succeed())
+		templateCondition := findCondition(updated.Status.Conditions, ConditionTypeTemplateValid)
+		Expect(templateCondition).NotTo(BeNil())
+		Expect(templateCondition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(templateCondition.Reason).To(Equal("KindNotAllowed"))
+		Expect(updated.Status.Resources).To(BeEmpty())
+
+		secret := &corev1.Secret{}
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: targetNs, Name: "copied-secret"}, secret)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,496 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+// targetSource is an AppIngress, ClusterAppIngress or ResourceDuplicator as seen by the reconcile
+// steps both reconcilers share: resolving and gating the target namespaces, summarizing the
+// outcome into conditions, removing stale objects and handling the finalizer.
+type targetSource struct {
+	obj        client.Object
+	conditions *[]metav1.Condition
+
+	// explicit are the target namespaces listed by name, selector selects more of them by labels
+	explicit []string
+	selector *metav1.LabelSelector
+
+	// objects names what is written into the target namespaces in messages, e.g. "Ingresses"
+	objects string
+}
+
+// appIngressSource returns the AppIngress or ClusterAppIngress as a target source
+func appIngressSource(appIngress ingressv1alpha1.AppIngressObject) *targetSource {
+	return &targetSource{
+		obj:        appIngress,
+		conditions: &appIngress.GetStatus().Conditions,
+		explicit:   appIngress.GetSpec().ExplicitTargetNamespaces(),
+		selector:   appIngress.GetSpec().NamespaceSelector,
+		objects:    "Ingresses",
+	}
+}
+
+// duplicatorSource returns the ResourceDuplicator as a target source
+func duplicatorSource(duplicator *ingressv1alpha1.ResourceDuplicator) *targetSource {
+	return &targetSource{
+		obj:        duplicator,
+		conditions: &duplicator.Status.Conditions,
+		explicit:   duplicator.Spec.ExplicitTargetNamespaces(),
+		selector:   duplicator.Spec.NamespaceSelector,
+		objects:    "objects",
+	}
+}
+
+// setCondition sets the condition on the source, stamped with the generation it was computed from.
+// It reports whether the condition changed.
+func (s *targetSource) setCondition(condition metav1.Condition) bool {
+	condition.ObservedGeneration = s.obj.GetGeneration()
+	return meta.SetStatusCondition(s.conditions, condition)
+}
+
+// targetGate decides which of the resolved target namespaces a source may write into
+type targetGate struct {
+	// requireConsent skips target namespaces that do not accept objects from the namespace of the
+	// source. Sources without a namespace need no consent.
+	requireConsent bool
+
+	// denials returns a message for every resolved target namespace the source may not write into
+	denials func(ctx context.Context, namespaces []string) (map[string]string, error)
+
+	// notEnforced explains why AppIngressPolicies are not enforced for the source, empty when they are
+	notEnforced string
+}
+
+// targetNamespaces are the target namespaces of a source sorted by whether it may write into them
+type targetNamespaces struct {
+	// allowed exist and may be written into
+	allowed []string
+
+	// missing do not exist or are terminating
+	missing []string
+
+	// notPermitted did not consent to objects from the namespace of the source
+	notPermitted []string
+
+	// denials map the namespaces the source may not write into to the reason
+	denials map[string]string
+}
+
+// resolveTargets resolves the target namespaces of the source, skips the ones it may not write into
+// and sets the NamespaceValid and PolicyAllowed conditions. Missing namespaces are reported and
+// skipped, the Namespace watch triggers reconciliation once they are created. Objects already
+// written into skipped namespaces are removed together with the other stale ones. It returns nil
+// when the namespace selector is invalid, which the NamespaceValid condition reports.
+func resolveTargets(ctx context.Context, c client.Client, recorder record.EventRecorder, source *targetSource,
+	gate targetGate) (*targetNamespaces, error) {
+	logger := log.FromContext(ctx)
+
+	var selector labels.Selector
+	if source.selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(source.selector); err != nil {
+			logger.Error(err, "Invalid namespace selector")
+			source.setCondition(metav1.Condition{
+				Type:    ConditionTypeNamespaceValid,
+				Status:  metav1.ConditionFalse,
+				Reason:  "InvalidSelector",
+				Message: "Invalid namespace selector: " + err.Error(),
+			})
+			return nil, nil
+		}
+	}
+	targets := &targetNamespaces{}
+	var err error
+	targets.allowed, targets.missing, err = resolveTargetNamespaces(ctx, c, source.explicit, selector)
+	if err != nil {
+		return nil, err
+	}
+	sourceNamespace := source.obj.GetNamespace()
+	if gate.requireConsent && sourceNamespace != "" {
+		if targets.allowed, targets.notPermitted, err = filterConsentingNamespaces(ctx, c, sourceNamespace,
+			targets.allowed); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case len(targets.missing) > 0:
+		logger.Info("Target namespaces not found", "namespaces", targets.missing)
+		if source.setCondition(metav1.Condition{
+			Type:    ConditionTypeNamespaceValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NotFound",
+			Message: "Target namespace does not exist: " + strings.Join(targets.missing, ", "),
+		}) {
+			recorder.Eventf(source.obj, corev1.EventTypeWarning, "NamespaceNotFound",
+				"Target namespace does not exist: %s", strings.Join(targets.missing, ", "))
+		}
+	case len(targets.notPermitted) > 0:
+		logger.Info("Target namespaces did not consent", "namespaces", targets.notPermitted)
+		message := fmt.Sprintf("Target namespace does not accept %s from namespace %s, "+
+			"set the %s label or annotation on it: %s", source.objects, sourceNamespace,
+			ingressv1alpha1.AcceptFromKey, strings.Join(targets.notPermitted, ", "))
+		if source.setCondition(metav1.Condition{
+			Type:    ConditionTypeNamespaceValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NotPermitted",
+			Message: message,
+		}) {
+			recorder.Event(source.obj, corev1.EventTypeWarning, "NamespaceNotPermitted", message)
+		}
+	default:
+		source.setCondition(metav1.Condition{
+			Type:    ConditionTypeNamespaceValid,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Valid",
+			Message: "Target namespace exists",
+		})
+	}
+
+	if targets.denials, err = gate.denials(ctx, targets.allowed); err != nil {
+		return nil, err
+	}
+	if setPolicyCondition(source, gate.notEnforced, targets.denials) && len(targets.denials) > 0 {
+		logger.Info("Target namespaces denied", "namespaces", slices.Sorted(maps.Keys(targets.denials)))
+		recorder.Event(source.obj, corev1.EventTypeWarning, "PolicyDenied",
+			meta.FindStatusCondition(*source.conditions, ConditionTypePolicyAllowed).Message)
+	}
+	targets.allowed = slices.DeleteFunc(targets.allowed, func(namespace string) bool {
+		_, denied := targets.denials[namespace]
+		return denied
+	})
+	return targets, nil
+}
+
+// skipped returns the status of every target namespace the source does not write into
+func (t *targetNamespaces) skipped(source *targetSource) []ingressv1alpha1.TargetStatus {
+	var targets []ingressv1alpha1.TargetStatus
+	for _, namespace := range t.missing {
+		targets = append(targets, ingressv1alpha1.TargetStatus{
+			Namespace: namespace,
+			Status:    metav1.ConditionFalse,
+			Reason:    "NotFound",
+			Message:   "Target namespace does not exist",
+		})
+	}
+	for _, namespace := range t.notPermitted {
+		targets = append(targets, ingressv1alpha1.TargetStatus{
+			Namespace: namespace,
+			Status:    metav1.ConditionFalse,
+			Reason:    "NotPermitted",
+			Message:   fmt.Sprintf("Target namespace does not accept %s from namespace %s", source.objects, source.obj.GetNamespace()),
+		})
+	}
+	for _, namespace := range slices.Sorted(maps.Keys(t.denials)) {
+		targets = append(targets, ingressv1alpha1.TargetStatus{
+			Namespace: namespace,
+			Status:    metav1.ConditionFalse,
+			Reason:    "PolicyDenied",
+			Message:   t.denials[namespace],
+		})
+	}
+	return targets
+}
+
+// resolveTargetNamespaces returns the existing target namespaces, combining the explicitly listed
+// ones with those matching the selector, and the listed ones that are missing. A nil selector
+// selects nothing. A terminating namespace is treated as missing, since the objects inside it are
+// about to be removed together with the namespace.
+func resolveTargetNamespaces(ctx context.Context, reader client.Reader, explicit []string,
+	selector labels.Selector) ([]string, []string, error) {
+	var namespaces, missing []string
+	for _, name := range explicit {
+		targetNs := &corev1.Namespace{}
+		if err := reader.Get(ctx, client.ObjectKey{Name: name}, targetNs); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, nil, err
+			}
+			missing = append(missing, name)
+			continue
+		}
+		if !targetNs.DeletionTimestamp.IsZero() {
+			missing = append(missing, name)
+			continue
+		}
+		namespaces = append(namespaces, name)
+	}
+
+	if selector != nil {
+		selected := &corev1.NamespaceList{}
+		if err := reader.List(ctx, selected, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, nil, err
+		}
+		for _, targetNs := range selected.Items {
+			if targetNs.DeletionTimestamp.IsZero() && !slices.Contains(namespaces, targetNs.Name) {
+				namespaces = append(namespaces, targetNs.Name)
+			}
+		}
+	}
+
+	slices.Sort(namespaces)
+	slices.Sort(missing)
+	return namespaces, missing, nil
+}
+
+// addDeniedNamespaces adds a message for every target namespace in denied to denials. kind names
+// the object for the message, e.g. "an AppIngress".
+func addDeniedNamespaces(denials map[string]string, denied, namespaces []string, kind string) {
+	for _, namespace := range namespaces {
+		if slices.Contains(denied, namespace) {
+			denials[namespace] = fmt.Sprintf("namespace %s may not be targeted by %s", namespace, kind)
+		}
+	}
+}
+
+// filterConsentingNamespaces splits the target namespaces into those accepting objects from the
+// source namespace and those that do not
+func filterConsentingNamespaces(ctx context.Context, reader client.Reader, source string,
+	namespaces []string) ([]string, []string, error) {
+	var permitted, notPermitted []string
+	for _, name := range namespaces {
+		targetNs := &corev1.Namespace{}
+		if err := reader.Get(ctx, client.ObjectKey{Name: name}, targetNs); err != nil {
+			return nil, nil, err
+		}
+		if ingressv1alpha1.AcceptsFrom(targetNs, source) {
+			permitted = append(permitted, name)
+		} else {
+			notPermitted = append(notPermitted, name)
+		}
+	}
+	return permitted, notPermitted, nil
+}
+
+// setPolicyCondition reports whether every target namespace may be written into. notEnforced
+// explains why AppIngressPolicies are not enforced, empty when they are. It reports whether the
+// condition changed.
+func setPolicyCondition(source *targetSource, notEnforced string, denials map[string]string) bool {
+	condition := metav1.Condition{
+		Type:    ConditionTypePolicyAllowed,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Allowed",
+		Message: "Every target namespace is allowed by an AppIngressPolicy",
+	}
+	switch {
+	case len(denials) > 0:
+		messages := make([]string, 0, len(denials))
+		for _, namespace := range slices.Sorted(maps.Keys(denials)) {
+			messages = append(messages, denials[namespace])
+		}
+		condition.Status, condition.Reason = metav1.ConditionFalse, "Denied"
+		condition.Message = strings.Join(messages, "; ")
+	case notEnforced != "":
+		condition.Reason, condition.Message = "NotEnforced", notEnforced
+	}
+	return source.setCondition(condition)
+}
+
+// outcomes counts the target namespaces of a source by what happened to them
+type outcomes struct {
+	written, missing, notPermitted, denied, blocked, unrendered int
+}
+
+// countOutcomes counts the target namespaces skipped by resolveTargets and the ones written into
+func (t *targetNamespaces) countOutcomes(written int) outcomes {
+	return outcomes{written: written, missing: len(t.missing), notPermitted: len(t.notPermitted),
+		denied: len(t.denials)}
+}
+
+// setOutputConditions summarizes the per-namespace outcomes into the created and conflict
+// conditions of the source. kind names the written object, owner the kind of the source.
+func setOutputConditions(source *targetSource, createdType, conflictType, kind, owner string, counts outcomes,
+	conflicts []*ingressConflictError, errs []error) {
+	if len(conflicts) > 0 {
+		messages := make([]string, 0, len(conflicts))
+		for _, conflict := range conflicts {
+			messages = append(messages, conflict.Error())
+		}
+		source.setCondition(metav1.Condition{
+			Type:    conflictType,
+			Status:  metav1.ConditionTrue,
+			Reason:  conflicts[0].reason,
+			Message: strings.Join(messages, "; "),
+		})
+	} else {
+		source.setCondition(metav1.Condition{
+			Type:    conflictType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Owned",
+			Message: fmt.Sprintf("%s is managed by this %s", kind, owner),
+		})
+	}
+
+	condition := metav1.Condition{
+		Type:    createdType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Created",
+		Message: fmt.Sprintf("%s created/updated successfully in %d namespace(s)", kind, counts.written),
+	}
+	switch {
+	case len(errs) > 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "Error"
+		condition.Message = fmt.Sprintf("Failed to create/update %s: %v", kind, kerrors.NewAggregate(errs))
+	case len(conflicts) > 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "Conflict"
+		condition.Message = fmt.Sprintf("%s already exists and is not managed by this %s", kind, owner)
+	case counts.written == 0 && counts.unrendered > 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "RenderFailed"
+		condition.Message = kind + " cannot be created until the template renders"
+	case counts.written == 0 && counts.blocked > 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "HostConflict"
+		condition.Message = kind + " cannot be created while another owner claims its hosts"
+	case counts.written == 0 && counts.missing > 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "NamespaceNotFound"
+		condition.Message = kind + " cannot be created until the target namespace exists"
+	case counts.written == 0 && counts.notPermitted > 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "NamespaceNotPermitted"
+		condition.Message = kind + " cannot be created until the target namespace accepts it"
+	case counts.written == 0 && counts.denied > 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "PolicyDenied"
+		condition.Message = kind + " may not be created in any target namespace"
+	case counts.written == 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "NoTargetNamespaces"
+		condition.Message = "No namespace matches the namespace selector"
+	}
+	source.setCondition(condition)
+}
+
+// readyCheck is a condition the Ready condition depends on and the status it has when healthy
+type readyCheck struct {
+	conditionType string
+	healthy       metav1.ConditionStatus
+}
+
+// summarizeReady returns ready unless one of the checked conditions is unhealthy, in which case
+// the reason and message of the first one are surfaced. It reports whether all of them are healthy.
+func summarizeReady(source *targetSource, ready metav1.Condition, checks ...readyCheck) (metav1.Condition, bool) {
+	for _, check := range checks {
+		if !meta.IsStatusConditionPresentAndEqual(*source.conditions, check.conditionType, check.healthy) {
+			ready.Status = metav1.ConditionFalse
+			ready.Reason, ready.Message = unhealthyReason(*source.conditions, check.conditionType)
+			return ready, false
+		}
+	}
+	return ready, true
+}
+
+// unhealthyReason returns the reason and message of the given condition, or placeholders when
+// the condition has not been set yet
+func unhealthyReason(conditions []metav1.Condition, conditionType string) (string, string) {
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	if condition == nil {
+		return "Reconciling", conditionType + " has not been evaluated yet"
+	}
+	return condition.Reason, condition.Message
+}
+
+// deleteUnkept deletes the referenced objects except the ones in keep. It returns the references
+// that could not be deleted so they can be retried.
+func deleteUnkept[R comparable](refs, keep []R, deleteRef func(R) error) ([]R, error) {
+	var remaining []R
+	var errs []error
+	for _, ref := range refs {
+		if slices.Contains(keep, ref) {
+			continue
+		}
+		if err := deleteRef(ref); err != nil {
+			remaining = append(remaining, ref)
+			errs = append(errs, err)
+		}
+	}
+	return remaining, kerrors.NewAggregate(errs)
+}
+
+// pruneStale removes the recorded objects that are no longer kept through cleanup, e.g. after a
+// rename or when a namespace stops being targeted, and returns the references to record: the kept
+// ones and the ones that could not be removed, sorted. Failures are reported in an event on the
+// source. what names the objects in messages, e.g. "Ingresses".
+func pruneStale[R comparable](ctx context.Context, recorder record.EventRecorder, source client.Object,
+	what string, recorded, keep []R, cleanup func(refs, keep []R) ([]R, error),
+	compare func(a, b R) int) ([]R, error) {
+	remaining, err := cleanup(recorded, keep)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to delete stale "+what)
+		recorder.Eventf(source, corev1.EventTypeWarning, "CleanupFailed", "Failed to delete stale %s: %v", what, err)
+	}
+	for _, ref := range keep {
+		if !slices.Contains(remaining, ref) {
+			remaining = append(remaining, ref)
+		}
+	}
+	slices.SortFunc(remaining, compare)
+	return remaining, err
+}
+
+// cleanupStep deletes the objects of one kind written for a source being deleted. what names
+// them in messages, e.g. "Ingress".
+type cleanupStep struct {
+	what    string
+	cleanup func() error
+}
+
+// finalize runs the cleanup steps of a source being deleted in order and removes the finalizer once
+// all of them succeeded. Sources without the finalizer are left alone.
+func finalize(ctx context.Context, c client.Client, recorder record.EventRecorder, source client.Object,
+	steps ...cleanupStep) error {
+	logger := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(source, finalizerName) {
+		return nil
+	}
+	for _, step := range steps {
+		if err := step.cleanup(); err != nil {
+			logger.Error(err, "Failed to delete "+step.what+" during cleanup")
+			recorder.Eventf(source, corev1.EventTypeWarning, "CleanupFailed",
+				"Failed to delete %s during cleanup: %v", step.what, err)
+			return err
+		}
+	}
+	controllerutil.RemoveFinalizer(source, finalizerName)
+	if err := c.Update(ctx, source); err != nil {
+		return err
+	}
+	logger.Info("Cleanup completed successfully")
+	return nil
+}
+
+// ensureFinalizer adds the finalizer to the source so the objects written for it are removed
+// before it is deleted
+func ensureFinalizer(ctx context.Context, c client.Client, source client.Object) error {
+	if controllerutil.ContainsFinalizer(source, finalizerName) {
+		return nil
+	}
+	controllerutil.AddFinalizer(source, finalizerName)
+	return c.Update(ctx, source)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/render"
+)
+
+// sourceNamespaceLabels returns the labels of the namespace of the AppIngress, which are available
+// to the template. A ClusterAppIngress has no namespace and no labels.
+func (r *AppIngressReconciler) sourceNamespaceLabels(ctx context.Context,
+	appIngress ingressv1alpha1.AppIngressObject) (map[string]string, error) {
+	if appIngress.GetNamespace() == "" {
+		return nil, nil
+	}
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: appIngress.GetNamespace()}, namespace); err != nil {
+		return nil, err
+	}
+	return namespace.Labels, nil
+}
+
+// renderAppIngress returns a copy of the AppIngress with the template rendered for the target
+// namespace, so everything written into the namespace is derived from the rendered values
+func renderAppIngress(appIngress ingressv1alpha1.AppIngressObject, sourceLabels map[string]string,
+	namespace string) (ingressv1alpha1.AppIngressObject, error) {
+	vars := render.NewVariables(appIngress, sourceLabels, namespace)
+	template, errs := render.Template(&appIngress.GetSpec().Template, vars, field.NewPath("spec", "template"))
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("namespace %s: %w", namespace, errs.ToAggregate())
+	}
+	rendered := appIngress.DeepCopyObject().(ingressv1alpha1.AppIngressObject)
+	rendered.GetSpec().Template = *template
+	return rendered, nil
+}
+
+// indexValues returns the values of the index for the AppIngress as written in its spec, with
+// render.TemplatedIndexValue in place of the templated ones
+func indexValues(appIngress ingressv1alpha1.AppIngressObject, index func(ingressv1alpha1.AppIngressObject) []string) []string {
+	return render.IndexValues(index(appIngress))
+}
+
+// renderedIndexValues returns the values of the index for the AppIngress rendered for every target
+// namespace known from its spec and status, so lookups use the names the target namespaces see.
+// Values that do not depend on the target namespace are included even without targets, templated
+// ones only once rendered. Templates that cannot be rendered are left out, the reconciler reports them.
+func (r *AppIngressReconciler) renderedIndexValues(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	index func(ingressv1alpha1.AppIngressObject) []string) ([]string, error) {
+	var values []string
+	add := func(appIngress ingressv1alpha1.AppIngressObject) {
+		for _, value := range index(appIngress) {
+			if !render.IsTemplated(value) && !slices.Contains(values, value) {
+				values = append(values, value)
+			}
+		}
+	}
+	add(appIngress)
+	if !slices.Contains(indexValues(appIngress, index), render.TemplatedIndexValue) {
+		return values, nil
+	}
+
+	sourceLabels, err := r.sourceNamespaceLabels(ctx, appIngress)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range render.Targets(appIngress) {
+		if rendered, err := renderAppIngress(appIngress, sourceLabels, target); err == nil {
+			add(rendered)
+		}
+	}
+	return values, nil
+}
+
+// listAppIngressesIndexed returns the AppIngresses and ClusterAppIngresses with the value under the
+// index once rendered for their target namespaces. Only the ones indexed under the value itself or
+// render.TemplatedIndexValue are listed, and only the latter are rendered.
+func (r *AppIngressReconciler) listAppIngressesIndexed(ctx context.Context, key,
+	value string) ([]ingressv1alpha1.AppIngressObject, error) {
+	appIngresses, err := r.listAppIngresses(ctx, client.MatchingFields{key: value})
+	if err != nil {
+		return nil, err
+	}
+	templated, err := r.listAppIngresses(ctx, client.MatchingFields{key: render.TemplatedIndexValue})
+	if err != nil {
+		return nil, err
+	}
+	for _, appIngress := range templated {
+		if slices.ContainsFunc(appIngresses, func(matched ingressv1alpha1.AppIngressObject) bool {
+			return client.ObjectKeyFromObject(matched) == client.ObjectKeyFromObject(appIngress)
+		}) {
+			continue
+		}
+		values, err := r.renderedIndexValues(ctx, appIngress, appIngressIndexes[key])
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(values, value) {
+			appIngresses = append(appIngresses, appIngress)
+		}
+	}
+	return appIngresses, nil
+}
+
+// setTemplateCondition reports whether the template could be rendered for every target namespace.
+// It reports whether the condition changed.
+func setTemplateCondition(appIngress ingressv1alpha1.AppIngressObject, errs []error) bool {
+	condition := metav1.Condition{
+		Type:    ConditionTypeTemplateRendered,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Rendered",
+		Message: "Template is rendered for every target namespace",
+	}
+	if len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		condition.Status, condition.Reason = metav1.ConditionFalse, "RenderFailed"
+		condition.Message = strings.Join(messages, "; ")
+	}
+	return setCondition(appIngress, condition)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+// tlsSecretIndexKey indexes AppIngresses by the "<namespace>/<name>" of the Secrets they copy
+const tlsSecretIndexKey = ".spec.tlsSecretSource"
+
+// tlsSecretNames returns the Secrets referenced by the TLS entries of the template, without duplicates
+func tlsSecretNames(appIngress ingressv1alpha1.AppIngressObject) []string {
+	var names []string
+	for _, tls := range appIngress.GetSpec().Template.Spec.TLS {
+		if tls.SecretName != "" && !slices.Contains(names, tls.SecretName) {
+			names = append(names, tls.SecretName)
+		}
+	}
+	return names
+}
+
+// tlsSecretSources returns the "<namespace>/<name>" keys of the Secrets copied by the AppIngress
+func tlsSecretSources(appIngress ingressv1alpha1.AppIngressObject) []string {
+	if appIngress.GetSpec().TLSSecretSource == nil {
+		return nil
+	}
+	var keys []string
+	for _, name := range tlsSecretNames(appIngress) {
+		keys = append(keys, appIngress.GetSpec().TLSSecretSource.Namespace+"/"+name)
+	}
+	return keys
+}
+
+// tlsSecretRefs returns the references of the Secret copies in the given target namespace. Nothing
+// is copied into the source namespace itself.
+func tlsSecretRefs(appIngress ingressv1alpha1.AppIngressObject, namespace string) []ingressv1alpha1.ResourceReference {
+	source := appIngress.GetSpec().TLSSecretSource
+	if source == nil || source.Namespace == namespace {
+		return nil
+	}
+	var refs []ingressv1alpha1.ResourceReference
+	for _, name := range tlsSecretNames(appIngress) {
+		refs = append(refs, ingressv1alpha1.ResourceReference{Namespace: namespace, Name: name})
+	}
+	return refs
+}
+
+// reconcileTLSSecrets copies the TLS Secrets of the AppIngress into the target namespace. It returns
+// the copies that may exist afterwards, so they are tracked even when some of them failed, and the
+// errors per Secret. Copies whose source is no longer shared are left out so they get removed.
+func (r *AppIngressReconciler) reconcileTLSSecrets(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	namespace string) ([]ingressv1alpha1.ResourceReference, []error) {
+	var keep []ingressv1alpha1.ResourceReference
+	var errs []error
+	for _, ref := range tlsSecretRefs(appIngress, namespace) {
+		err := r.reconcileTLSSecret(ctx, appIngress, ref)
+		var syncErr *syncError
+		if !errors.As(err, &syncErr) || syncErr.reason != "NotShared" {
+			keep = append(keep, ref)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return keep, errs
+}
+
+// reconcileTLSSecret applies a copy of the source Secret. A copy made for another AppIngress from the
+// same source is left alone, while any other Secret with the same name is never overwritten.
+func (r *AppIngressReconciler) reconcileTLSSecret(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject,
+	ref ingressv1alpha1.ResourceReference) error {
+	sourceNamespace := appIngress.GetSpec().TLSSecretSource.Namespace
+	copiedFrom := sourceNamespace + "/" + ref.Name
+
+	source := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: sourceNamespace, Name: ref.Name}, source); err != nil {
+		if apierrors.IsNotFound(err) {
+			return &syncError{
+				reason:  "SourceNotFound",
+				message: fmt.Sprintf("TLS Secret %s does not exist", copiedFrom),
+			}
+		}
+		return err
+	}
+	// ClusterAppIngresses are created by cluster administrators and may copy any Secret
+	if appIngress.GetNamespace() != "" && !ingressv1alpha1.SharedWith(source, appIngress.GetNamespace()) {
+		return &syncError{
+			reason: "NotShared",
+			message: fmt.Sprintf("TLS Secret %s is not shared with namespace %s, list it in the %s annotation",
+				copiedFrom, appIngress.GetNamespace(), ingressv1alpha1.ShareWithAnnotation),
+		}
+	}
+
+	existing := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	} else if !ingressv1alpha1.IsManagedBy(existing, sourceKey(appIngress)) {
+		if ingressv1alpha1.IsManaged(existing) && existing.Annotations[ingressv1alpha1.CopiedFromAnnotation] == copiedFrom {
+			// Copied for another AppIngress from the same source, which keeps it in sync
+			return nil
+		}
+		return &syncError{
+			reason:  "Conflict",
+			message: fmt.Sprintf("Secret %s/%s already exists and is not a copy of %s", ref.Namespace, ref.Name, copiedFrom),
+		}
+	}
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.Name,
+			Namespace: ref.Namespace,
+			Labels:    map[string]string{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue},
+			Annotations: map[string]string{
+				ingressv1alpha1.SourceAnnotation:     sourceKey(appIngress),
+				ingressv1alpha1.CopiedFromAnnotation: copiedFrom,
+			},
+		},
+		Type: source.Type,
+		Data: source.Data,
+	}
+	return r.Patch(ctx, secret, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+}
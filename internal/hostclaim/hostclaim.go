@@ -0,0 +1,401 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hostclaim detects AppIngresses and ClusterAppIngresses routing the same host and path as
+// another one of them or, optionally, an Ingress not managed by the controller. It is shared by the
+// reconciler, which lets the oldest claim win, and the validating webhook, which rejects new
+// collisions.
+package hostclaim
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/render"
+)
+
+// Claim is a host and path pair routed by an Ingress
+type Claim struct {
+	Host string
+	Path string
+}
+
+func (c Claim) String() string {
+	return c.Host + c.Path
+}
+
+// Claims returns the host and path pairs routed by the Ingress spec, without duplicates.
+// Rules without a host match every host and are not treated as claims, and neither are templated
+// hosts and paths, which differ between target namespaces and are claimed once rendered.
+func Claims(spec *networkingv1.IngressSpec) []Claim {
+	var claims []Claim
+	for _, rule := range spec.Rules {
+		claims = appendRuleClaims(claims, rule)
+	}
+	return claims
+}
+
+// appendRuleClaims appends the claims of the rule that are not in claims yet
+func appendRuleClaims(claims []Claim, rule networkingv1.IngressRule) []Claim {
+	add := func(claim Claim) {
+		if !slices.Contains(claims, claim) {
+			claims = append(claims, claim)
+		}
+	}
+	if rule.Host == "" || render.IsTemplated(rule.Host) {
+		return claims
+	}
+	if rule.HTTP == nil || len(rule.HTTP.Paths) == 0 {
+		add(Claim{Host: rule.Host})
+		return claims
+	}
+	for _, path := range rule.HTTP.Paths {
+		if !render.IsTemplated(path.Path) {
+			add(Claim{Host: rule.Host, Path: path.Path})
+		}
+	}
+	return claims
+}
+
+// isTemplated reports whether the host or a path of the rule is templated
+func isTemplated(rule networkingv1.IngressRule) bool {
+	if render.IsTemplated(rule.Host) {
+		return true
+	}
+	return rule.HTTP != nil && slices.ContainsFunc(rule.HTTP.Paths, func(path networkingv1.HTTPIngressPath) bool {
+		return render.IsTemplated(path.Path)
+	})
+}
+
+// HostIndexKey indexes AppIngresses and ClusterAppIngresses by the hosts of their template rules,
+// with render.TemplatedIndexValue in place of templated hosts, and Ingresses not managed by the
+// controller by the hosts of their rules
+const HostIndexKey = ".spec.template.spec.rules.host"
+
+var (
+	indexedMu sync.Mutex
+	indexed   = map[client.FieldIndexer]bool{}
+)
+
+// IndexFields registers HostIndexKey with the indexer. The controller and the webhooks may share a
+// manager, so registering it again with the same indexer does nothing.
+func IndexFields(ctx context.Context, indexer client.FieldIndexer) error {
+	indexedMu.Lock()
+	defer indexedMu.Unlock()
+	if indexed[indexer] {
+		return nil
+	}
+	for _, obj := range []client.Object{&ingressv1alpha1.AppIngress{}, &ingressv1alpha1.ClusterAppIngress{}} {
+		if err := indexer.IndexField(ctx, obj, HostIndexKey, func(obj client.Object) []string {
+			return render.IndexValues(Hosts(obj.(ingressv1alpha1.AppIngressObject)))
+		}); err != nil {
+			return err
+		}
+	}
+	if err := indexer.IndexField(ctx, &networkingv1.Ingress{}, HostIndexKey, func(obj client.Object) []string {
+		ingress := obj.(*networkingv1.Ingress)
+		if ingressv1alpha1.IsManaged(ingress) {
+			return nil
+		}
+		var hosts []string
+		for _, claim := range Claims(&ingress.Spec) {
+			if !slices.Contains(hosts, claim.Host) {
+				hosts = append(hosts, claim.Host)
+			}
+		}
+		return hosts
+	}); err != nil {
+		return err
+	}
+	indexed[indexer] = true
+	return nil
+}
+
+// Hosts returns the hosts of the rules of the AppIngress template as written in its spec, including
+// templated ones, without duplicates
+func Hosts(appIngress ingressv1alpha1.AppIngressObject) []string {
+	var hosts []string
+	for _, rule := range appIngress.GetSpec().Template.Spec.Rules {
+		if rule.Host != "" && !slices.Contains(hosts, rule.Host) {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	return hosts
+}
+
+// Conflict is a claim of the AppIngress that is also held by another owner
+type Conflict struct {
+	Claim Claim
+
+	// Owner describes the other object in the "<Kind> <namespace>/<name>" form, or "<Kind> <name>"
+	// for a ClusterAppIngress
+	Owner string
+
+	// Rule is the index of the first rule of the AppIngress template routing the claim
+	Rule int
+}
+
+// Finder looks up the claims held by other AppIngresses and ClusterAppIngresses and, optionally,
+// unmanaged Ingresses. Unmanaged Ingresses the AppIngress would adopt are not reported.
+type Finder struct {
+	// Reader is used to list AppIngresses, ClusterAppIngresses and Ingresses, and to look up the
+	// namespace labels templates are rendered with
+	Reader client.Reader
+
+	// IncludeUnmanaged also reports claims held by Ingresses not managed by the controller
+	IncludeUnmanaged bool
+
+	// Indexed only lists the objects indexed under the claimed hosts or render.TemplatedIndexValue by
+	// HostIndexKey, which IndexFields must have registered with the cache Reader reads from.
+	// Otherwise every AppIngress, ClusterAppIngress and Ingress is listed.
+	Indexed bool
+}
+
+// Conflicts returns the claims the AppIngress makes in the target namespaces that are also held by
+// another owner. Templated hosts are rendered for every target namespace, the ones of other
+// AppIngresses for the target namespaces in their spec and status. When olderOnly is set, only
+// owners created before the AppIngress are reported, so the oldest claim wins; ties are broken by
+// namespace and name.
+func (f *Finder) Conflicts(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject, targets []string,
+	olderOnly bool) ([]Conflict, error) {
+	sourceLabels := map[string]map[string]string{}
+	rules, err := f.ruleClaims(ctx, appIngress, targets, sourceLabels)
+	if err != nil {
+		return nil, err
+	}
+	claims := slices.Concat(rules...)
+	if len(claims) == 0 {
+		return nil, nil
+	}
+
+	var conflicts []Conflict
+	check := func(kind string, obj client.Object, otherClaims []Claim) {
+		if olderOnly && !olderThan(obj, appIngress) {
+			return
+		}
+		owner := kind + " " + obj.GetName()
+		if obj.GetNamespace() != "" {
+			owner = kind + " " + obj.GetNamespace() + "/" + obj.GetName()
+		}
+		for _, claim := range otherClaims {
+			if !slices.Contains(claims, claim) {
+				continue
+			}
+			rule := slices.IndexFunc(rules, func(ruleClaims []Claim) bool { return slices.Contains(ruleClaims, claim) })
+			conflicts = append(conflicts, Conflict{Claim: claim, Owner: owner, Rule: rule})
+		}
+	}
+	checkAppIngress := func(kind string, other ingressv1alpha1.AppIngressObject) error {
+		otherRules, err := f.ruleClaims(ctx, other, render.Targets(other), sourceLabels)
+		if err != nil {
+			return err
+		}
+		var otherClaims []Claim
+		for _, ruleClaims := range otherRules {
+			for _, claim := range ruleClaims {
+				if !slices.Contains(otherClaims, claim) {
+					otherClaims = append(otherClaims, claim)
+				}
+			}
+		}
+		check(kind, other, otherClaims)
+		return nil
+	}
+
+	var hosts []string
+	for _, claim := range claims {
+		if !slices.Contains(hosts, claim.Host) {
+			hosts = append(hosts, claim.Host)
+		}
+	}
+	appIngressHosts := append(slices.Clone(hosts), render.TemplatedIndexValue)
+
+	appIngresses, err := f.list(ctx, func() client.ObjectList { return &ingressv1alpha1.AppIngressList{} },
+		appIngressHosts)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range appIngresses {
+		other := obj.(*ingressv1alpha1.AppIngress)
+		if other.Namespace == appIngress.GetNamespace() && other.Name == appIngress.GetName() {
+			continue
+		}
+		if err := checkAppIngress("AppIngress", other); err != nil {
+			return nil, err
+		}
+	}
+	// AppIngresses always have a namespace, so the namespace and name tell the kinds apart
+	clusterAppIngresses, err := f.list(ctx,
+		func() client.ObjectList { return &ingressv1alpha1.ClusterAppIngressList{} }, appIngressHosts)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range clusterAppIngresses {
+		other := obj.(*ingressv1alpha1.ClusterAppIngress)
+		if appIngress.GetNamespace() == "" && other.Name == appIngress.GetName() {
+			continue
+		}
+		if err := checkAppIngress("ClusterAppIngress", other); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.IncludeUnmanaged {
+		ingresses, err := f.list(ctx, func() client.ObjectList { return &networkingv1.IngressList{} }, hosts)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range ingresses {
+			ingress := obj.(*networkingv1.Ingress)
+			if !ingressv1alpha1.IsManaged(ingress) && !adopts(appIngress, targets, ingress) {
+				check("Ingress", ingress, Claims(&ingress.Spec))
+			}
+		}
+	}
+
+	slices.SortFunc(conflicts, func(a, b Conflict) int {
+		if c := strings.Compare(a.Claim.String(), b.Claim.String()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Owner, b.Owner)
+	})
+	return conflicts, nil
+}
+
+// list returns the objects of the kind of the list indexed under any of the values by HostIndexKey,
+// each of them once, or every object of the kind when the finder is not indexed
+func (f *Finder) list(ctx context.Context, newList func() client.ObjectList, values []string) ([]client.Object, error) {
+	if !f.Indexed {
+		list := newList()
+		if err := f.Reader.List(ctx, list); err != nil {
+			return nil, err
+		}
+		return items(list)
+	}
+	var objs []client.Object
+	for _, value := range values {
+		list := newList()
+		if err := f.Reader.List(ctx, list, client.MatchingFields{HostIndexKey: value}); err != nil {
+			return nil, err
+		}
+		listed, err := items(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range listed {
+			if !slices.ContainsFunc(objs, func(other client.Object) bool {
+				return client.ObjectKeyFromObject(other) == client.ObjectKeyFromObject(obj)
+			}) {
+				objs = append(objs, obj)
+			}
+		}
+	}
+	return objs, nil
+}
+
+// items returns the items of the list
+func items(list client.ObjectList) ([]client.Object, error) {
+	listed, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]client.Object, 0, len(listed))
+	for _, obj := range listed {
+		objs = append(objs, obj.(client.Object))
+	}
+	return objs, nil
+}
+
+// adopts reports whether the AppIngress would adopt the unmanaged Ingress instead of colliding
+// with it: the Ingress has the name of the template in a target namespace and the adoption policy
+// allows taking over unmanaged Ingresses.
+func adopts(appIngress ingressv1alpha1.AppIngressObject, targets []string, ingress *networkingv1.Ingress) bool {
+	spec := appIngress.GetSpec()
+	if spec.OutputKind == ingressv1alpha1.OutputKindHTTPRoute {
+		return false
+	}
+	if spec.AdoptionPolicy != ingressv1alpha1.AdoptionPolicyIfUnowned &&
+		spec.AdoptionPolicy != ingressv1alpha1.AdoptionPolicyAlways {
+		return false
+	}
+	return ingress.Name == spec.Template.Name && slices.Contains(targets, ingress.Namespace)
+}
+
+// ruleClaims returns the claims of every rule of the AppIngress template: the ones that do not
+// depend on the target namespace and the ones the rule renders to in each target namespace.
+// sourceLabels caches the labels of the namespaces of the AppIngresses.
+func (f *Finder) ruleClaims(ctx context.Context, appIngress ingressv1alpha1.AppIngressObject, targets []string,
+	sourceLabels map[string]map[string]string) ([][]Claim, error) {
+	spec := &appIngress.GetSpec().Template.Spec
+	rules := make([][]Claim, len(spec.Rules))
+	var templated bool
+	for i, rule := range spec.Rules {
+		rules[i] = appendRuleClaims(nil, rule)
+		templated = templated || isTemplated(rule)
+	}
+	if !templated || len(targets) == 0 {
+		return rules, nil
+	}
+
+	labels, ok := sourceLabels[appIngress.GetNamespace()]
+	if !ok && appIngress.GetNamespace() != "" {
+		namespace := &corev1.Namespace{}
+		err := f.Reader.Get(ctx, client.ObjectKey{Name: appIngress.GetNamespace()}, namespace)
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		labels = namespace.Labels
+		sourceLabels[appIngress.GetNamespace()] = labels
+	}
+	templates := render.Templates(appIngress, labels, targets)
+	for _, target := range slices.Sorted(maps.Keys(templates)) {
+		for i, rule := range templates[target].Spec.Rules {
+			rules[i] = appendRuleClaims(rules[i], rule)
+		}
+	}
+	return rules, nil
+}
+
+// olderThan reports whether obj was created before appIngress, breaking ties by namespace and name.
+// Objects that were not created yet, e.g. during admission, are the newest.
+func olderThan(obj client.Object, appIngress ingressv1alpha1.AppIngressObject) bool {
+	a, b := creationTime(obj), creationTime(appIngress)
+	if !a.Equal(b) {
+		return a.Before(b)
+	}
+	if c := strings.Compare(obj.GetNamespace(), appIngress.GetNamespace()); c != 0 {
+		return c < 0
+	}
+	return obj.GetName() < appIngress.GetName()
+}
+
+// creationTime returns the creation timestamp of obj, or the far future if it was not created yet
+func creationTime(obj client.Object) time.Time {
+	if created := obj.GetCreationTimestamp(); !created.IsZero() {
+		return created.Time
+	}
+	return time.Unix(1<<62, 0)
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostclaim_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/hostclaim"
+	"github.com/rafal-jan/ingress-duplicator/internal/render"
+)
+
+// builderIndexer registers the indexes of hostclaim.IndexFields with a fake client builder
+type builderIndexer struct {
+	builder *fake.ClientBuilder
+}
+
+func (b builderIndexer) IndexField(_ context.Context, obj client.Object, field string,
+	extractValue client.IndexerFunc) error {
+	b.builder.WithIndex(obj, field, extractValue)
+	return nil
+}
+
+var _ = Describe("HostClaim", func() {
+	var (
+		ctx     context.Context
+		scheme  *runtime.Scheme
+		created = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	rule := func(host string, paths ...string) networkingv1.IngressRule {
+		rule := networkingv1.IngressRule{Host: host}
+		if len(paths) > 0 {
+			rule.HTTP = &networkingv1.HTTPIngressRuleValue{}
+			for _, path := range paths {
+				rule.HTTP.Paths = append(rule.HTTP.Paths, networkingv1.HTTPIngressPath{Path: path})
+			}
+		}
+		return rule
+	}
+
+	newAppIngress := func(namespace, name string, age time.Duration,
+		rules ...networkingv1.IngressRule) *ingressv1alpha1.AppIngress {
+		return &ingressv1alpha1.AppIngress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.Time{Time: created.Add(-age)},
+			},
+			Spec: ingressv1alpha1.AppIngressSpec{
+				Template: ingressv1alpha1.IngressTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: name},
+					Spec:       networkingv1.IngressSpec{Rules: rules},
+				},
+				TargetNamespaces: []string{"team-b", "team-c"},
+			},
+		}
+	}
+
+	newIngress := func(namespace, name string, rules ...networkingv1.IngressRule) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.Time{Time: created.Add(-time.Hour)},
+			},
+			Spec: networkingv1.IngressSpec{Rules: rules},
+		}
+	}
+
+	newFinder := func(indexed bool, objs ...client.Object) *hostclaim.Finder {
+		builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...)
+		if indexed {
+			Expect(hostclaim.IndexFields(ctx, builderIndexer{builder: builder})).To(Succeed())
+		}
+		return &hostclaim.Finder{Reader: builder.Build(), IncludeUnmanaged: true, Indexed: indexed}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(ingressv1alpha1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	DescribeTable("Claims",
+		func(rules []networkingv1.IngressRule, expected []hostclaim.Claim) {
+			Expect(hostclaim.Claims(&networkingv1.IngressSpec{Rules: rules})).To(Equal(expected))
+		},
+		Entry("claims a host without paths", []networkingv1.IngressRule{rule("web.example.com")},
+			[]hostclaim.Claim{{Host: "web.example.com"}}),
+		Entry("claims every path of a host without duplicates", []networkingv1.IngressRule{
+			rule("web.example.com", "/a", "/b"), rule("web.example.com", "/a"),
+		}, []hostclaim.Claim{{Host: "web.example.com", Path: "/a"}, {Host: "web.example.com", Path: "/b"}}),
+		Entry("ignores rules without a host", []networkingv1.IngressRule{rule("", "/a")}, nil),
+		Entry("ignores templated hosts", []networkingv1.IngressRule{rule("{{ .TargetNamespace }}.example.com")}, nil),
+		Entry("ignores templated paths", []networkingv1.IngressRule{
+			rule("web.example.com", "/{{ .TargetNamespace }}", "/static"),
+		}, []hostclaim.Claim{{Host: "web.example.com", Path: "/static"}}),
+	)
+
+	Describe("Hosts", func() {
+		It("should return the hosts as written, including templated ones, without duplicates", func() {
+			appIngress := newAppIngress("team-a", "web", 0, rule("web.example.com", "/a"),
+				rule("{{ .TargetNamespace }}.example.com"), rule(""), rule("web.example.com", "/b"))
+			Expect(hostclaim.Hosts(appIngress)).To(Equal([]string{"web.example.com", "{{ .TargetNamespace }}.example.com"}))
+			Expect(render.IndexValues(hostclaim.Hosts(appIngress))).
+				To(Equal([]string{"web.example.com", render.TemplatedIndexValue}))
+		})
+	})
+
+	Describe("Finder", func() {
+		DescribeTable("should report the claims held by other owners",
+			func(indexed bool) {
+				appIngress := newAppIngress("team-a", "web", 0,
+					rule("web.example.com", "/"), rule("{{ .TargetNamespace }}.example.com"))
+				finder := newFinder(indexed,
+					appIngress,
+					// Claims web.example.com/ as well
+					newAppIngress("team-x", "older", time.Hour, rule("web.example.com", "/")),
+					// Renders to team-c.example.com in its target namespaces
+					newAppIngress("team-x", "templated", -time.Hour, rule("{{ .TargetNamespace }}.example.com")),
+					// Routes another path
+					newAppIngress("team-x", "other-path", time.Hour, rule("web.example.com", "/api")),
+					// Created at the same time, but wins the tie by its empty namespace
+					&ingressv1alpha1.ClusterAppIngress{
+						ObjectMeta: metav1.ObjectMeta{Name: "status", CreationTimestamp: metav1.Time{Time: created}},
+						Spec: ingressv1alpha1.AppIngressSpec{
+							Template: ingressv1alpha1.IngressTemplate{
+								Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{rule("team-b.example.com")}},
+							},
+						},
+					},
+					newIngress("team-y", "unmanaged", rule("web.example.com", "/")),
+				)
+
+				conflicts, err := finder.Conflicts(ctx, appIngress, []string{"team-b", "team-c"}, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(conflicts).To(Equal([]hostclaim.Conflict{
+					{Claim: hostclaim.Claim{Host: "team-b.example.com"}, Owner: "AppIngress team-x/templated", Rule: 1},
+					{Claim: hostclaim.Claim{Host: "team-b.example.com"}, Owner: "ClusterAppIngress status", Rule: 1},
+					{Claim: hostclaim.Claim{Host: "team-c.example.com"}, Owner: "AppIngress team-x/templated", Rule: 1},
+					{Claim: hostclaim.Claim{Host: "web.example.com", Path: "/"}, Owner: "AppIngress team-x/older"},
+					{Claim: hostclaim.Claim{Host: "web.example.com", Path: "/"}, Owner: "Ingress team-y/unmanaged"},
+				}))
+
+				By("only reporting older owners when the oldest claim wins")
+				conflicts, err = finder.Conflicts(ctx, appIngress, []string{"team-b", "team-c"}, true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(conflicts).To(Equal([]hostclaim.Conflict{
+					{Claim: hostclaim.Claim{Host: "team-b.example.com"}, Owner: "ClusterAppIngress status", Rule: 1},
+					{Claim: hostclaim.Claim{Host: "web.example.com", Path: "/"}, Owner: "AppIngress team-x/older"},
+					{Claim: hostclaim.Claim{Host: "web.example.com", Path: "/"}, Owner: "Ingress team-y/unmanaged"},
+				}))
+			},
+			Entry("listing every object", false),
+			Entry("listing the indexed objects", true),
+		)
+
+		DescribeTable("should leave out unmanaged Ingresses it does not report",
+			func(indexed bool) {
+				appIngress := newAppIngress("team-a", "web", 0, rule("web.example.com"))
+				appIngress.Spec.AdoptionPolicy = ingressv1alpha1.AdoptionPolicyIfUnowned
+				managed := newIngress("team-b", "managed", rule("web.example.com"))
+				managed.Labels = map[string]string{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue}
+				managed.Annotations = map[string]string{ingressv1alpha1.SourceAnnotation: "team-x/other"}
+				finder := newFinder(indexed, appIngress, managed,
+					// Would be adopted, it has the name of the template in a target namespace
+					newIngress("team-b", "web", rule("web.example.com")),
+					newIngress("team-y", "unrelated", rule("other.example.com")),
+				)
+
+				conflicts, err := finder.Conflicts(ctx, appIngress, []string{"team-b"}, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(conflicts).To(BeEmpty())
+
+				By("reporting the Ingress once the AppIngress no longer adopts it")
+				appIngress.Spec.AdoptionPolicy = ingressv1alpha1.AdoptionPolicyNever
+				conflicts, err = finder.Conflicts(ctx, appIngress, []string{"team-b"}, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(conflicts).To(Equal([]hostclaim.Conflict{
+					{Claim: hostclaim.Claim{Host: "web.example.com"}, Owner: "Ingress team-b/web"},
+				}))
+
+				By("ignoring unmanaged Ingresses unless asked to")
+				finder.IncludeUnmanaged = false
+				Expect(finder.Conflicts(ctx, appIngress, []string{"team-b"}, false)).To(BeEmpty())
+			},
+			Entry("listing every object", false),
+			Entry("listing the indexed objects", true),
+		)
+
+		It("should render templates with the labels of the source namespace", func() {
+			appIngress := newAppIngress("team-a", "web", 0, rule("web.example.com"))
+			other := newAppIngress("team-x", "labelled", time.Hour, rule("{{ .SourceNamespace.Labels.app }}.example.com"))
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-x", Labels: map[string]string{"app": "web"}}}
+
+			conflicts, err := newFinder(true, appIngress, other, namespace).Conflicts(ctx, appIngress, []string{"team-b"}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conflicts).To(Equal([]hostclaim.Conflict{
+				{Claim: hostclaim.Claim{Host: "web.example.com"}, Owner: "AppIngress team-x/labelled"},
+			}))
+
+			By("leaving out templates that cannot be rendered without the namespace")
+			conflicts, err = newFinder(true, appIngress, other).Conflicts(ctx, appIngress, []string{"team-b"}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conflicts).To(BeEmpty())
+		})
+
+		It("should not look anything up for an AppIngress without claims", func() {
+			appIngress := newAppIngress("team-a", "web", 0, rule(""))
+			finder := &hostclaim.Finder{Reader: fake.NewClientBuilder().Build()}
+			Expect(finder.Conflicts(ctx, appIngress, []string{"team-b"}, false)).To(BeEmpty())
+		})
+	})
+})
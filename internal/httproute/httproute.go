@@ -0,0 +1,135 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httproute translates the Ingress template of an AppIngress into a Gateway API HTTPRoute.
+// It is shared by the reconciler, which writes the HTTPRoutes, and the validating webhook, which
+// rejects templates that cannot be translated.
+package httproute
+
+import (
+	"fmt"
+	"slices"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// FromIngress translates the Ingress spec into an HTTPRoute spec attached to the given parents.
+// Hosts become hostnames and every path becomes a rule matching it, with the default backend as a
+// catch-all rule. TLS entries are left out, since TLS is terminated by the Gateway listeners.
+//
+// An HTTPRoute applies all of its rules to all of its hostnames, so rules of different hosts must
+// route the same paths. Only Service backends referenced by port number can be translated. path is
+// the field path of the Ingress spec.
+func FromIngress(spec *networkingv1.IngressSpec, parentRefs []gatewayv1.ParentReference,
+	path *field.Path) (*gatewayv1.HTTPRouteSpec, field.ErrorList) {
+	var allErrs field.ErrorList
+	route := &gatewayv1.HTTPRouteSpec{}
+	for _, parentRef := range parentRefs {
+		route.ParentRefs = append(route.ParentRefs, *parentRef.DeepCopy())
+	}
+
+	var paths []networkingv1.HTTPIngressPath
+	anyHost := false
+	for i, rule := range spec.Rules {
+		rulePath := path.Child("rules").Index(i)
+		var rulePaths []networkingv1.HTTPIngressPath
+		if rule.HTTP != nil {
+			rulePaths = rule.HTTP.Paths
+		}
+		if i == 0 {
+			paths = rulePaths
+		} else if !equality.Semantic.DeepEqual(rulePaths, paths) {
+			allErrs = append(allErrs, field.Invalid(rulePath.Child("http"), rule.Host,
+				"every rule must route the same paths to be translated into a single HTTPRoute"))
+		}
+
+		if rule.Host == "" {
+			anyHost = true
+			continue
+		}
+		if hostname := gatewayv1.Hostname(rule.Host); !slices.Contains(route.Hostnames, hostname) {
+			route.Hostnames = append(route.Hostnames, hostname)
+		}
+	}
+	if anyHost && len(route.Hostnames) > 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("rules"), "",
+			"a rule without a host cannot be combined with rules for specific hosts in a single HTTPRoute"))
+	}
+
+	// Paths are the same for every rule, so the ones of the first rule are translated
+	for j, ingressPath := range paths {
+		pathPath := path.Child("rules").Index(0).Child("http", "paths").Index(j)
+		ref, err := backendRef(&ingressPath.Backend, pathPath.Child("backend"))
+		if err != nil {
+			allErrs = append(allErrs, err)
+			continue
+		}
+		route.Rules = append(route.Rules, gatewayv1.HTTPRouteRule{
+			Matches:     []gatewayv1.HTTPRouteMatch{{Path: pathMatch(&ingressPath)}},
+			BackendRefs: []gatewayv1.HTTPBackendRef{*ref},
+		})
+	}
+	if spec.DefaultBackend != nil {
+		ref, err := backendRef(spec.DefaultBackend, path.Child("defaultBackend"))
+		if err != nil {
+			allErrs = append(allErrs, err)
+		} else {
+			// A rule without matches matches every request
+			route.Rules = append(route.Rules, gatewayv1.HTTPRouteRule{
+				BackendRefs: []gatewayv1.HTTPBackendRef{*ref},
+			})
+		}
+	}
+	return route, allErrs
+}
+
+// pathMatch translates the path of an Ingress rule. Implementation-specific paths are treated as
+// prefixes, which is what most ingress controllers do.
+func pathMatch(ingressPath *networkingv1.HTTPIngressPath) *gatewayv1.HTTPPathMatch {
+	matchType := gatewayv1.PathMatchPathPrefix
+	if ingressPath.PathType != nil && *ingressPath.PathType == networkingv1.PathTypeExact {
+		matchType = gatewayv1.PathMatchExact
+	}
+	value := ingressPath.Path
+	if value == "" {
+		value = "/"
+	}
+	return &gatewayv1.HTTPPathMatch{Type: &matchType, Value: &value}
+}
+
+// backendRef translates an Ingress backend into a reference to the same Service port
+func backendRef(backend *networkingv1.IngressBackend, path *field.Path) (*gatewayv1.HTTPBackendRef, *field.Error) {
+	if backend.Service == nil {
+		return nil, field.Required(path.Child("service"), "only Service backends can be translated into an HTTPRoute")
+	}
+	if backend.Service.Port.Name != "" {
+		return nil, field.Invalid(path.Child("service", "port", "name"), backend.Service.Port.Name,
+			fmt.Sprintf("HTTPRoutes refer to Service ports by number, use the number of port %s",
+				backend.Service.Port.Name))
+	}
+	port := gatewayv1.PortNumber(backend.Service.Port.Number)
+	return &gatewayv1.HTTPBackendRef{
+		BackendRef: gatewayv1.BackendRef{
+			BackendObjectReference: gatewayv1.BackendObjectReference{
+				Name: gatewayv1.ObjectName(backend.Service.Name),
+				Port: &port,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httproute_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/rafal-jan/ingress-duplicator/internal/httproute"
+)
+
+// ptrTo returns a pointer to the value
+func ptrTo[T any](value T) *T {
+	return &value
+}
+
+var _ = Describe("HTTPRoute", func() {
+	var (
+		parentRefs []gatewayv1.ParentReference
+		path       = field.NewPath("spec", "template", "spec")
+	)
+
+	backend := func(service string, port int32) networkingv1.IngressBackend {
+		return networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+			Name: service,
+			Port: networkingv1.ServiceBackendPort{Number: port},
+		}}
+	}
+
+	rule := func(host string, paths ...networkingv1.HTTPIngressPath) networkingv1.IngressRule {
+		rule := networkingv1.IngressRule{Host: host}
+		if len(paths) > 0 {
+			rule.HTTP = &networkingv1.HTTPIngressRuleValue{Paths: paths}
+		}
+		return rule
+	}
+
+	backendRef := func(service string, port gatewayv1.PortNumber) []gatewayv1.HTTPBackendRef {
+		return []gatewayv1.HTTPBackendRef{{BackendRef: gatewayv1.BackendRef{
+			BackendObjectReference: gatewayv1.BackendObjectReference{
+				Name: gatewayv1.ObjectName(service),
+				Port: ptrTo(port),
+			},
+		}}}
+	}
+
+	match := func(matchType gatewayv1.PathMatchType, value string) []gatewayv1.HTTPRouteMatch {
+		return []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Type: ptrTo(matchType), Value: ptrTo(value)}}}
+	}
+
+	BeforeEach(func() {
+		parentRefs = []gatewayv1.ParentReference{{Name: "gateway", Namespace: ptrTo(gatewayv1.Namespace("infra"))}}
+	})
+
+	It("should translate hosts, paths and the default backend", func() {
+		paths := []networkingv1.HTTPIngressPath{
+			{Path: "/api", PathType: ptrTo(networkingv1.PathTypePrefix), Backend: backend("api", 80)},
+			{Path: "/healthz", PathType: ptrTo(networkingv1.PathTypeExact), Backend: backend("web", 80)},
+			{Backend: backend("web", 80)},
+		}
+		spec := &networkingv1.IngressSpec{
+			DefaultBackend: ptrTo(backend("fallback", 8080)),
+			Rules: []networkingv1.IngressRule{
+				rule("web.example.com", paths...),
+				rule("www.example.com", paths...),
+				rule("web.example.com", paths...),
+			},
+			TLS: []networkingv1.IngressTLS{{Hosts: []string{"web.example.com"}, SecretName: "web-tls"}},
+		}
+
+		route, errs := httproute.FromIngress(spec, parentRefs, path)
+		Expect(errs).To(BeEmpty())
+		Expect(route).To(Equal(&gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: parentRefs},
+			Hostnames:       []gatewayv1.Hostname{"web.example.com", "www.example.com"},
+			Rules: []gatewayv1.HTTPRouteRule{
+				{Matches: match(gatewayv1.PathMatchPathPrefix, "/api"), BackendRefs: backendRef("api", 80)},
+				{Matches: match(gatewayv1.PathMatchExact, "/healthz"), BackendRefs: backendRef("web", 80)},
+				{Matches: match(gatewayv1.PathMatchPathPrefix, "/"), BackendRefs: backendRef("web", 80)},
+				{BackendRefs: backendRef("fallback", 8080)},
+			},
+		}))
+
+		By("copying the parents")
+		parentRefs[0].Name = "changed"
+		Expect(route.ParentRefs[0].Name).To(Equal(gatewayv1.ObjectName("gateway")))
+	})
+
+	It("should translate a rule without a host into a route for every host", func() {
+		spec := &networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{
+			rule("", networkingv1.HTTPIngressPath{Path: "/", Backend: backend("web", 80)}),
+		}}
+		route, errs := httproute.FromIngress(spec, parentRefs, path)
+		Expect(errs).To(BeEmpty())
+		Expect(route.Hostnames).To(BeEmpty())
+		Expect(route.Rules).To(HaveLen(1))
+	})
+
+	DescribeTable("should reject Ingresses that cannot be translated",
+		func(spec *networkingv1.IngressSpec, expected field.ErrorList) {
+			_, errs := httproute.FromIngress(spec, parentRefs, path)
+			Expect(errs).To(HaveLen(len(expected)))
+			for i, err := range errs {
+				Expect(err.Type).To(Equal(expected[i].Type))
+				Expect(err.Field).To(Equal(expected[i].Field))
+			}
+		},
+		Entry("rules routing different paths", &networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{
+			rule("web.example.com", networkingv1.HTTPIngressPath{Path: "/", Backend: backend("web", 80)}),
+			rule("api.example.com", networkingv1.HTTPIngressPath{Path: "/api", Backend: backend("api", 80)}),
+		}}, field.ErrorList{field.Invalid(path.Child("rules").Index(1).Child("http"), nil, "")}),
+		Entry("a rule without a host next to specific hosts", &networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{
+			rule("web.example.com", networkingv1.HTTPIngressPath{Path: "/", Backend: backend("web", 80)}),
+			rule("", networkingv1.HTTPIngressPath{Path: "/", Backend: backend("web", 80)}),
+		}}, field.ErrorList{field.Invalid(path.Child("rules"), nil, "")}),
+		Entry("a resource backend", &networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{
+			rule("web.example.com", networkingv1.HTTPIngressPath{Path: "/", Backend: networkingv1.IngressBackend{}}),
+		}}, field.ErrorList{
+			field.Required(path.Child("rules").Index(0).Child("http", "paths").Index(0).Child("backend", "service"), ""),
+		}),
+		Entry("a Service port referenced by name", &networkingv1.IngressSpec{
+			DefaultBackend: &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+				Name: "web",
+				Port: networkingv1.ServiceBackendPort{Name: "http"},
+			}},
+		}, field.ErrorList{field.Invalid(path.Child("defaultBackend", "service", "port", "name"), nil, "")}),
+	)
+})
@@ -0,0 +1,189 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy evaluates AppIngresses and ResourceDuplicators against the AppIngressPolicies in
+// the cluster. It is shared by the reconcilers and the validating webhooks so both reach the same
+// verdict.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/render"
+)
+
+// Evaluator decides whether an AppIngress may generate Ingresses in a target namespace
+type Evaluator struct {
+	// Reader is used to look up policies and namespace labels
+	Reader client.Reader
+}
+
+// Denials returns a message for every target namespace the AppIngress may not write into.
+// A namespace is allowed when a policy matching the source namespace allows the target namespace
+// and every host the template renders to there. Namespaces that do not exist can only be allowed
+// by name.
+func (e *Evaluator) Denials(ctx context.Context, appIngress *ingressv1alpha1.AppIngress,
+	targets []string) (map[string]string, error) {
+	return e.denials(ctx, appIngress.Namespace, targets, func(source *corev1.Namespace, target string) []string {
+		var sourceLabels map[string]string
+		if source != nil {
+			sourceLabels = source.Labels
+		}
+		// Hosts of a template that cannot be rendered are left out, nothing is written with them
+		rendered, _ := render.Template(&appIngress.Spec.Template,
+			render.NewVariables(appIngress, sourceLabels, target), field.NewPath("spec", "template"))
+		return slices.DeleteFunc(Hosts(rendered), render.IsTemplated)
+	})
+}
+
+// NamespaceDenials returns a message for every target namespace no policy allows the source
+// namespace to write into. Hosts are not checked, so it applies to objects that route no traffic,
+// such as the ones created by ResourceDuplicators.
+func (e *Evaluator) NamespaceDenials(ctx context.Context, sourceNamespace string,
+	targets []string) (map[string]string, error) {
+	return e.denials(ctx, sourceNamespace, targets, func(*corev1.Namespace, string) []string { return nil })
+}
+
+// denials evaluates the policies matching the source namespace for every target namespace and the
+// hosts used there
+func (e *Evaluator) denials(ctx context.Context, sourceNamespace string, targets []string,
+	hostsFor func(source *corev1.Namespace, target string) []string) (map[string]string, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	policies := &ingressv1alpha1.AppIngressPolicyList{}
+	if err := e.Reader.List(ctx, policies); err != nil {
+		return nil, err
+	}
+	source, err := e.getNamespace(ctx, sourceNamespace)
+	if err != nil {
+		return nil, err
+	}
+	var applicable []ingressv1alpha1.AppIngressPolicy
+	for _, policy := range policies.Items {
+		if matchesNamespace(sourceNamespace, source, policy.Spec.SourceNamespaces,
+			policy.Spec.SourceNamespaceSelector) {
+			applicable = append(applicable, policy)
+		}
+	}
+
+	denials := map[string]string{}
+	for _, target := range targets {
+		targetNs, err := e.getNamespace(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		hosts := hostsFor(source, target)
+		var namespaceAllowed, hostsAllowed bool
+		var deniedHosts []string
+		for _, policy := range applicable {
+			if !matchesNamespace(target, targetNs, policy.Spec.TargetNamespaces, policy.Spec.TargetNamespaceSelector) {
+				continue
+			}
+			namespaceAllowed = true
+			deniedHosts = slices.DeleteFunc(slices.Clone(hosts), func(host string) bool {
+				return HostAllowed(host, policy.Spec.Hostnames)
+			})
+			if len(deniedHosts) == 0 {
+				hostsAllowed = true
+				break
+			}
+		}
+		switch {
+		case !namespaceAllowed:
+			denials[target] = fmt.Sprintf("no AppIngressPolicy allows namespace %s to target namespace %s",
+				sourceNamespace, target)
+		case !hostsAllowed:
+			denials[target] = fmt.Sprintf("no AppIngressPolicy allows namespace %s to use host %s in namespace %s",
+				sourceNamespace, strings.Join(deniedHosts, ", "), target)
+		}
+	}
+	return denials, nil
+}
+
+// getNamespace returns the namespace with the given name, or nil when it does not exist
+func (e *Evaluator) getNamespace(ctx context.Context, name string) (*corev1.Namespace, error) {
+	namespace := &corev1.Namespace{}
+	if err := e.Reader.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return namespace, nil
+}
+
+// matchesNamespace reports whether the namespace is listed by name or matches the selector.
+// The selector is only evaluated when the namespace exists.
+func matchesNamespace(name string, namespace *corev1.Namespace, names []string, selector *metav1.LabelSelector) bool {
+	if slices.Contains(names, name) {
+		return true
+	}
+	if selector == nil || namespace == nil {
+		return false
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	return err == nil && s.Matches(labels.Set(namespace.Labels))
+}
+
+// Hosts returns the hosts used by the rules and TLS entries of the template, without duplicates
+func Hosts(template *ingressv1alpha1.IngressTemplate) []string {
+	var hosts []string
+	add := func(host string) {
+		if host != "" && !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	for _, rule := range template.Spec.Rules {
+		add(rule.Host)
+	}
+	for _, tls := range template.Spec.TLS {
+		for _, host := range tls.Hosts {
+			add(host)
+		}
+	}
+	return hosts
+}
+
+// HostAllowed reports whether host is covered by the allowed hostnames. An allowed entry "*.example.com"
+// covers every subdomain of example.com, including wildcard hosts such as "*.app.example.com".
+// An empty list allows every host.
+func HostAllowed(host string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, pattern := range allowed {
+		if host == pattern {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok && strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/policy"
+)
+
+var _ = Describe("Policy", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	newEvaluator := func(objs ...client.Object) *policy.Evaluator {
+		return &policy.Evaluator{Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()}
+	}
+
+	newNamespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+
+	newPolicy := func(name string, spec ingressv1alpha1.AppIngressPolicySpec) *ingressv1alpha1.AppIngressPolicy {
+		return &ingressv1alpha1.AppIngressPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+
+	newAppIngress := func(hosts ...string) *ingressv1alpha1.AppIngress {
+		appIngress := &ingressv1alpha1.AppIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec:       ingressv1alpha1.AppIngressSpec{Parameters: map[string]string{"domain": "example.com"}},
+		}
+		for _, host := range hosts {
+			appIngress.Spec.Template.Spec.Rules = append(appIngress.Spec.Template.Spec.Rules,
+				networkingv1.IngressRule{Host: host})
+		}
+		return appIngress
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(ingressv1alpha1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	DescribeTable("HostAllowed",
+		func(host string, allowed []string, expected bool) {
+			Expect(policy.HostAllowed(host, allowed)).To(Equal(expected))
+		},
+		Entry("allows every host without hostnames", "web.example.com", nil, true),
+		Entry("allows every host with a bare wildcard", "web.example.org", []string{"*"}, true),
+		Entry("allows an exact match", "example.com", []string{"example.com"}, true),
+		Entry("allows a subdomain of a wildcard", "web.example.com", []string{"*.example.com"}, true),
+		Entry("allows a nested subdomain of a wildcard", "a.web.example.com", []string{"*.example.com"}, true),
+		Entry("allows a wildcard host under a wildcard", "*.web.example.com", []string{"*.example.com"}, true),
+		Entry("denies the apex of a wildcard", "example.com", []string{"*.example.com"}, false),
+		Entry("denies a subdomain of an exact hostname", "web.example.com", []string{"example.com"}, false),
+		Entry("denies a host sharing only the suffix", "webexample.com", []string{"*.example.com"}, false),
+		Entry("denies a host covered by no entry", "web.example.org",
+			[]string{"example.org", "*.example.com"}, false),
+	)
+
+	DescribeTable("Hosts",
+		func(template *ingressv1alpha1.IngressTemplate, expected []string) {
+			Expect(policy.Hosts(template)).To(Equal(expected))
+		},
+		Entry("returns nothing for a template without hosts", &ingressv1alpha1.IngressTemplate{}, nil),
+		Entry("returns rule and TLS hosts without duplicates", &ingressv1alpha1.IngressTemplate{
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{Host: "a.example.com"}, {}, {Host: "b.example.com"}},
+				TLS:   []networkingv1.IngressTLS{{Hosts: []string{"b.example.com", "c.example.com"}}},
+			},
+		}, []string{"a.example.com", "b.example.com", "c.example.com"}),
+	)
+
+	Describe("NamespaceDenials", func() {
+		DescribeTable("should match target namespaces by name or selector",
+			func(target string, namespaces []*corev1.Namespace, spec ingressv1alpha1.AppIngressPolicySpec, denied bool) {
+				objs := []client.Object{newPolicy("policy", spec)}
+				for _, namespace := range namespaces {
+					objs = append(objs, namespace)
+				}
+				denials, err := newEvaluator(objs...).NamespaceDenials(ctx, "team-a", []string{target})
+				Expect(err).NotTo(HaveOccurred())
+				if denied {
+					Expect(denials).To(HaveKeyWithValue(target,
+						"no AppIngressPolicy allows namespace team-a to target namespace "+target))
+				} else {
+					Expect(denials).To(BeEmpty())
+				}
+			},
+			Entry("allows an existing namespace listed by name", "team-b",
+				[]*corev1.Namespace{newNamespace("team-b", nil)},
+				ingressv1alpha1.AppIngressPolicySpec{SourceNamespaces: []string{"team-a"}, TargetNamespaces: []string{"team-b"}},
+				false),
+			Entry("allows a nonexistent namespace listed by name", "team-b", nil,
+				ingressv1alpha1.AppIngressPolicySpec{SourceNamespaces: []string{"team-a"}, TargetNamespaces: []string{"team-b"}},
+				false),
+			Entry("allows an existing namespace matching the selector", "team-b",
+				[]*corev1.Namespace{newNamespace("team-b", map[string]string{"tenant": "true"})},
+				ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaces:        []string{"team-a"},
+					TargetNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+				},
+				false),
+			Entry("denies a nonexistent namespace the selector would match", "team-b", nil,
+				ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaces:        []string{"team-a"},
+					TargetNamespaceSelector: &metav1.LabelSelector{},
+				},
+				true),
+			Entry("denies an existing namespace not matching the selector", "team-b",
+				[]*corev1.Namespace{newNamespace("team-b", nil)},
+				ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaces:        []string{"team-a"},
+					TargetNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+				},
+				true),
+			Entry("denies a namespace when the source namespace matches no policy", "team-b",
+				[]*corev1.Namespace{newNamespace("team-b", nil)},
+				ingressv1alpha1.AppIngressPolicySpec{SourceNamespaces: []string{"team-c"}, TargetNamespaces: []string{"team-b"}},
+				true),
+			Entry("allows a source namespace matching the selector", "team-b",
+				[]*corev1.Namespace{newNamespace("team-a", map[string]string{"tenant": "true"})},
+				ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+					TargetNamespaces:        []string{"team-b"},
+				},
+				false),
+			Entry("denies a nonexistent source namespace the selector would match", "team-b", nil,
+				ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaceSelector: &metav1.LabelSelector{},
+					TargetNamespaces:        []string{"team-b"},
+				},
+				true),
+		)
+
+		It("should return nothing without targets", func() {
+			Expect(newEvaluator().NamespaceDenials(ctx, "team-a", nil)).To(BeNil())
+		})
+	})
+
+	Describe("Denials", func() {
+		It("should deny hosts no policy allows in the target namespace", func() {
+			evaluator := newEvaluator(
+				newPolicy("team-b", ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaces: []string{"team-a"},
+					TargetNamespaces: []string{"team-b"},
+					Hostnames:        []string{"*.example.com"},
+				}),
+				newPolicy("team-c", ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaces: []string{"team-a"},
+					TargetNamespaces: []string{"team-c"},
+					Hostnames:        []string{"web.example.com"},
+				}),
+			)
+			denials, err := evaluator.Denials(ctx, newAppIngress("{{ .TargetNamespace }}.example.com", "example.com"),
+				[]string{"team-b", "team-c"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(denials).To(Equal(map[string]string{
+				"team-b": "no AppIngressPolicy allows namespace team-a to use host example.com in namespace team-b",
+				"team-c": "no AppIngressPolicy allows namespace team-a to use host team-c.example.com, example.com " +
+					"in namespace team-c",
+			}))
+		})
+
+		It("should allow hosts covered by any of the matching policies", func() {
+			evaluator := newEvaluator(
+				newPolicy("apex", ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaces: []string{"team-a"},
+					TargetNamespaces: []string{"team-b"},
+					Hostnames:        []string{"example.com"},
+				}),
+				newPolicy("everything", ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaces: []string{"team-a"},
+					TargetNamespaces: []string{"team-b"},
+					Hostnames:        []string{"*"},
+				}),
+			)
+			denials, err := evaluator.Denials(ctx, newAppIngress("web.{{ .Parameters.domain }}", "example.com"),
+				[]string{"team-b"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(denials).To(BeEmpty())
+		})
+
+		It("should leave out hosts that cannot be rendered", func() {
+			evaluator := newEvaluator(newPolicy("team-b", ingressv1alpha1.AppIngressPolicySpec{
+				SourceNamespaces: []string{"team-a"},
+				TargetNamespaces: []string{"team-b"},
+				Hostnames:        []string{"*.example.com"},
+			}))
+			denials, err := evaluator.Denials(ctx, newAppIngress("{{ .Parameters.missing }}.example.org"),
+				[]string{"team-b"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(denials).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,181 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package render expands the Go templates in the Ingress template of an AppIngress for a target
+// namespace. It is shared by the reconciler, which renders the template for every target namespace,
+// and the validating webhook, which rejects templates that cannot be rendered.
+package render
+
+import (
+	"slices"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+// Variables are the values available to the template
+type Variables struct {
+	// TargetNamespace is the namespace the Ingress is rendered for
+	TargetNamespace string
+
+	// AppIngress identifies the AppIngress owning the template
+	AppIngress Object
+
+	// SourceNamespace is the namespace of the AppIngress
+	SourceNamespace Namespace
+
+	// Parameters are the values of spec.parameters
+	Parameters map[string]string
+}
+
+// Object identifies a namespaced object
+type Object struct {
+	Name      string
+	Namespace string
+}
+
+// Namespace describes a namespace and its labels
+type Namespace struct {
+	Name   string
+	Labels map[string]string
+}
+
+// NewVariables returns the variables of the AppIngress or ClusterAppIngress for the target namespace.
+// sourceLabels are the labels of the namespace of the AppIngress; a ClusterAppIngress has neither a
+// namespace nor its labels.
+func NewVariables(appIngress ingressv1alpha1.AppIngressObject, sourceLabels map[string]string,
+	targetNamespace string) *Variables {
+	return &Variables{
+		TargetNamespace: targetNamespace,
+		AppIngress:      Object{Name: appIngress.GetName(), Namespace: appIngress.GetNamespace()},
+		SourceNamespace: Namespace{Name: appIngress.GetNamespace(), Labels: sourceLabels},
+		Parameters:      appIngress.GetSpec().Parameters,
+	}
+}
+
+// Targets returns the target namespaces known for the AppIngress: the explicitly listed ones and the
+// ones recorded in its status, which include the namespaces matched by the selector
+func Targets(appIngress ingressv1alpha1.AppIngressObject) []string {
+	targets := appIngress.GetSpec().ExplicitTargetNamespaces()
+	for _, target := range appIngress.GetStatus().Targets {
+		if !slices.Contains(targets, target.Namespace) {
+			targets = append(targets, target.Namespace)
+		}
+	}
+	return targets
+}
+
+// Templates renders the template of the AppIngress for every target namespace. Namespaces the
+// template cannot be rendered for are left out, the reconciler reports them.
+func Templates(appIngress ingressv1alpha1.AppIngressObject, sourceLabels map[string]string,
+	targets []string) map[string]*ingressv1alpha1.IngressTemplate {
+	templates := make(map[string]*ingressv1alpha1.IngressTemplate, len(targets))
+	for _, target := range targets {
+		rendered, errs := Template(&appIngress.GetSpec().Template, NewVariables(appIngress, sourceLabels, target),
+			field.NewPath("spec", "template"))
+		if len(errs) == 0 {
+			templates[target] = rendered
+		}
+	}
+	return templates
+}
+
+// IsTemplated reports whether the value contains a template action and depends on the variables
+func IsTemplated(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// TemplatedIndexValue is indexed in place of templated values. Rendering needs the labels of the
+// source namespace, which an index function cannot read, so the objects indexed under it are
+// rendered when they are looked up.
+const TemplatedIndexValue = "{{templated}}"
+
+// IndexValues returns the values without duplicates and with TemplatedIndexValue in place of the
+// templated ones, as field index functions return them
+func IndexValues(values []string) []string {
+	var indexed []string
+	for _, value := range values {
+		if IsTemplated(value) {
+			value = TemplatedIndexValue
+		}
+		if !slices.Contains(indexed, value) {
+			indexed = append(indexed, value)
+		}
+	}
+	return indexed
+}
+
+// Template returns a copy of the Ingress template with annotation values, rule hosts and paths, and
+// TLS hosts and secret names rendered. Values without template actions are copied verbatim. Missing
+// map keys, e.g. an unknown parameter, are errors. path is the field path of the template.
+func Template(ingressTemplate *ingressv1alpha1.IngressTemplate, vars *Variables,
+	path *field.Path) (*ingressv1alpha1.IngressTemplate, field.ErrorList) {
+	rendered := ingressTemplate.DeepCopy()
+	var allErrs field.ErrorList
+	expand := func(value *string, fldPath *field.Path) {
+		if !IsTemplated(*value) {
+			return
+		}
+		result, err := String(*value, vars)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, *value, err.Error()))
+			return
+		}
+		*value = result
+	}
+
+	for key, value := range rendered.Annotations {
+		expand(&value, path.Child("metadata", "annotations").Key(key))
+		rendered.Annotations[key] = value
+	}
+	specPath := path.Child("spec")
+	for i := range rendered.Spec.Rules {
+		rule := &rendered.Spec.Rules[i]
+		rulePath := specPath.Child("rules").Index(i)
+		expand(&rule.Host, rulePath.Child("host"))
+		if rule.HTTP == nil {
+			continue
+		}
+		for j := range rule.HTTP.Paths {
+			expand(&rule.HTTP.Paths[j].Path, rulePath.Child("http", "paths").Index(j).Child("path"))
+		}
+	}
+	for i := range rendered.Spec.TLS {
+		tls := &rendered.Spec.TLS[i]
+		tlsPath := specPath.Child("tls").Index(i)
+		for j := range tls.Hosts {
+			expand(&tls.Hosts[j], tlsPath.Child("hosts").Index(j))
+		}
+		expand(&tls.SecretName, tlsPath.Child("secretName"))
+	}
+	return rendered, allErrs
+}
+
+// String renders a single template value
+func String(value string, vars *Variables) (string, error) {
+	tmpl, err := template.New("value").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
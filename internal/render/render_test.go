@@ -0,0 +1,147 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/render"
+)
+
+var _ = Describe("Render", func() {
+	var (
+		appIngress *ingressv1alpha1.AppIngress
+		vars       *render.Variables
+	)
+
+	BeforeEach(func() {
+		appIngress = &ingressv1alpha1.AppIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: ingressv1alpha1.AppIngressSpec{
+				Parameters: map[string]string{"domain": "example.com"},
+				Template: ingressv1alpha1.IngressTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{"example.com/owner": "{{ .AppIngress.Name }}"},
+					},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{
+							Host: "{{ .TargetNamespace }}.{{ .Parameters.domain }}",
+							IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{{Path: "/{{ .SourceNamespace.Labels.team }}"}},
+							}},
+						}},
+						TLS: []networkingv1.IngressTLS{{
+							Hosts:      []string{"{{ .TargetNamespace }}.{{ .Parameters.domain }}"},
+							SecretName: "{{ .AppIngress.Namespace }}-tls",
+						}},
+					},
+				},
+			},
+		}
+		vars = render.NewVariables(appIngress, map[string]string{"team": "payments"}, "team-b")
+	})
+
+	Describe("Template", func() {
+		It("should render every templated field", func() {
+			rendered, errs := render.Template(&appIngress.Spec.Template, vars, field.NewPath("spec", "template"))
+			Expect(errs).To(BeEmpty())
+			Expect(rendered.Annotations).To(HaveKeyWithValue("example.com/owner", "web"))
+			Expect(rendered.Spec.Rules[0].Host).To(Equal("team-b.example.com"))
+			Expect(rendered.Spec.Rules[0].HTTP.Paths[0].Path).To(Equal("/payments"))
+			Expect(rendered.Spec.TLS[0].Hosts).To(ConsistOf("team-b.example.com"))
+			Expect(rendered.Spec.TLS[0].SecretName).To(Equal("team-a-tls"))
+
+			By("leaving the template itself untouched")
+			Expect(appIngress.Spec.Template.Spec.Rules[0].Host).To(Equal("{{ .TargetNamespace }}.{{ .Parameters.domain }}"))
+		})
+
+		It("should copy values without template actions verbatim", func() {
+			template := &ingressv1alpha1.IngressTemplate{
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{{Host: "web.example.com"}},
+					TLS:   []networkingv1.IngressTLS{{Hosts: []string{"web.example.com"}, SecretName: "web-tls"}},
+				},
+			}
+			rendered, errs := render.Template(template, vars, field.NewPath("spec", "template"))
+			Expect(errs).To(BeEmpty())
+			Expect(rendered).To(Equal(template))
+		})
+
+		DescribeTable("should report values that cannot be rendered at their field path",
+			func(mutate func(*ingressv1alpha1.IngressTemplate), path string, message string) {
+				mutate(&appIngress.Spec.Template)
+				_, errs := render.Template(&appIngress.Spec.Template, vars, field.NewPath("spec", "template"))
+				Expect(errs).To(HaveLen(1))
+				Expect(errs[0].Type).To(Equal(field.ErrorTypeInvalid))
+				Expect(errs[0].Field).To(Equal(path))
+				Expect(errs[0].Detail).To(ContainSubstring(message))
+			},
+			Entry("an unknown parameter", func(t *ingressv1alpha1.IngressTemplate) {
+				t.Spec.Rules[0].Host = "{{ .Parameters.missing }}.example.com"
+			}, "spec.template.spec.rules[0].host", `map has no entry for key "missing"`),
+			Entry("an unknown variable", func(t *ingressv1alpha1.IngressTemplate) {
+				t.Spec.Rules[0].HTTP.Paths[0].Path = "/{{ .Cluster }}"
+			}, "spec.template.spec.rules[0].http.paths[0].path", "can't evaluate field Cluster"),
+			Entry("a malformed template", func(t *ingressv1alpha1.IngressTemplate) {
+				t.Annotations["example.com/owner"] = "{{ .AppIngress.Name"
+			}, "spec.template.metadata.annotations[example.com/owner]", "unclosed action"),
+			Entry("an unknown function", func(t *ingressv1alpha1.IngressTemplate) {
+				t.Spec.TLS[0].Hosts[0] = "{{ upper .TargetNamespace }}.example.com"
+			}, "spec.template.spec.tls[0].hosts[0]", `function "upper" not defined`),
+			Entry("a missing source namespace label", func(t *ingressv1alpha1.IngressTemplate) {
+				t.Spec.TLS[0].SecretName = "{{ .SourceNamespace.Labels.tier }}-tls"
+			}, "spec.template.spec.tls[0].secretName", `map has no entry for key "tier"`),
+		)
+	})
+
+	Describe("Templates", func() {
+		It("should leave out the target namespaces the template cannot be rendered for", func() {
+			appIngress.Spec.Template.Spec.TLS[0].SecretName =
+				`{{ if eq .TargetNamespace "team-c" }}{{ .Parameters.missing }}{{ end }}team-b-tls`
+
+			templates := render.Templates(appIngress, map[string]string{"team": "payments"}, []string{"team-b", "team-c"})
+			Expect(templates).To(HaveLen(1))
+			Expect(templates).To(HaveKey("team-b"))
+			Expect(templates["team-b"].Spec.TLS[0].SecretName).To(Equal("team-b-tls"))
+		})
+	})
+
+	Describe("Targets", func() {
+		It("should return the explicit targets followed by the recorded ones", func() {
+			appIngress.Spec.TargetNamespaces = []string{"team-b", "team-c"}
+			appIngress.Status.Targets = []ingressv1alpha1.TargetStatus{{Namespace: "team-c"}, {Namespace: "team-d"}}
+			Expect(render.Targets(appIngress)).To(Equal([]string{"team-b", "team-c", "team-d"}))
+		})
+	})
+
+	DescribeTable("IndexValues",
+		func(values []string, expected []string) {
+			Expect(render.IndexValues(values)).To(Equal(expected))
+		},
+		Entry("returns nothing without values", nil, nil),
+		Entry("drops duplicates", []string{"a.example.com", "b.example.com", "a.example.com"},
+			[]string{"a.example.com", "b.example.com"}),
+		Entry("replaces templated values by a single marker",
+			[]string{"{{ .TargetNamespace }}.example.com", "a.example.com", "{{ .Parameters.domain }}"},
+			[]string{render.TemplatedIndexValue, "a.example.com"}),
+	)
+})
@@ -0,0 +1,143 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sourceref resolves the AppIngress or ClusterAppIngress an object was generated from
+// through the markers the controller sets on it. Owner references cannot cross namespaces, so the
+// markers are the only way back from a generated object to its source. It is shared by the kubectl
+// plugin, which traces generated Ingresses to their source, and the orphan sweeper, which cleans up
+// the Ingresses whose source is gone.
+package sourceref
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+// Key returns the key of the source named by the source annotation of a managed object. A
+// ClusterAppIngress is referenced by its name alone, so its key has no namespace.
+func Key(obj metav1.Object) (types.NamespacedName, bool) {
+	if !ingressv1alpha1.IsManaged(obj) {
+		return types.NamespacedName{}, false
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(obj.GetAnnotations()[ingressv1alpha1.SourceAnnotation])
+	if err != nil || name == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, true
+}
+
+// Describe names the kind and key of the source, e.g. "AppIngress team-a/web"
+func Describe(key types.NamespacedName) string {
+	if key.Namespace == "" {
+		return "ClusterAppIngress " + key.Name
+	}
+	return "AppIngress " + key.String()
+}
+
+// Get returns the source of the managed object, whether or not it still generates the object
+func Get(ctx context.Context, reader client.Reader, obj metav1.Object) (ingressv1alpha1.AppIngressObject, error) {
+	key, ok := Key(obj)
+	if !ok {
+		return nil, apierrors.NewBadRequest("object has no valid " + ingressv1alpha1.SourceAnnotation + " annotation")
+	}
+	var source ingressv1alpha1.AppIngressObject = &ingressv1alpha1.AppIngress{}
+	if key.Namespace == "" {
+		source = &ingressv1alpha1.ClusterAppIngress{}
+	}
+	if err := reader.Get(ctx, key, source); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// Reasons an object was left behind by its source
+const (
+	// ReasonSourceNotFound means the source no longer exists
+	ReasonSourceNotFound = "SourceNotFound"
+
+	// ReasonSourceRecreated means the source was recreated under the same name and the new source
+	// does not generate the object
+	ReasonSourceRecreated = "SourceRecreated"
+)
+
+// OrphanReason returns why the managed object was left behind by its source, or an empty string
+// when its source still exists or, once recreated, generates the object again. Objects not managed
+// by the controller are never orphaned.
+func OrphanReason(ctx context.Context, reader client.Reader, obj metav1.Object) (string, error) {
+	if _, ok := Key(obj); !ok {
+		return "", nil
+	}
+	source, err := Get(ctx, reader, obj)
+	if apierrors.IsNotFound(err) {
+		return ReasonSourceNotFound, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	uid := obj.GetAnnotations()[ingressv1alpha1.SourceUIDAnnotation]
+	if uid == "" || types.UID(uid) == source.GetUID() || Generates(source, obj) {
+		return "", nil
+	}
+	// The recreated source may target the namespace without having been reconciled yet, in which
+	// case the reconciler takes the object over
+	targeted, err := Targets(ctx, reader, source, obj)
+	if err != nil || targeted {
+		return "", err
+	}
+	return ReasonSourceRecreated, nil
+}
+
+// Generates reports whether the source lists the object among the Ingresses or HTTPRoutes it generated
+func Generates(source ingressv1alpha1.AppIngressObject, obj metav1.Object) bool {
+	ref := ingressv1alpha1.ResourceReference{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	status := source.GetStatus()
+	return slices.Contains(status.Ingresses, ref) || slices.Contains(status.HTTPRoutes, ref)
+}
+
+// Targets reports whether the source generates an Ingress with the name of the object in its
+// namespace, whether the namespace is listed by name or selected by its labels
+func Targets(ctx context.Context, reader client.Reader, source ingressv1alpha1.AppIngressObject,
+	obj metav1.Object) (bool, error) {
+	spec := source.GetSpec()
+	if spec.Template.Name != obj.GetName() || spec.OutputKind == ingressv1alpha1.OutputKindHTTPRoute {
+		return false, nil
+	}
+	if slices.Contains(spec.ExplicitTargetNamespaces(), obj.GetNamespace()) {
+		return true, nil
+	}
+	if spec.NamespaceSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector)
+	if err != nil {
+		return false, nil
+	}
+	namespace := &corev1.Namespace{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: obj.GetNamespace()}, namespace); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sourceref_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/sourceref"
+)
+
+var _ = Describe("SourceRef", func() {
+	var (
+		ctx     context.Context
+		scheme  *runtime.Scheme
+		ingress *networkingv1.Ingress
+	)
+
+	newIngress := func(source, uid string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "team-b",
+				Labels:    map[string]string{ingressv1alpha1.ManagedByLabel: ingressv1alpha1.ManagedByValue},
+				Annotations: map[string]string{
+					ingressv1alpha1.SourceAnnotation:    source,
+					ingressv1alpha1.SourceUIDAnnotation: uid,
+				},
+			},
+		}
+	}
+
+	newReader := func(objs ...client.Object) client.Reader {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	newAppIngress := func(uid types.UID, generated ...ingressv1alpha1.ResourceReference) *ingressv1alpha1.AppIngress {
+		return &ingressv1alpha1.AppIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", UID: uid},
+			Status:     ingressv1alpha1.AppIngressStatus{Ingresses: generated},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(ingressv1alpha1.AddToScheme(scheme)).To(Succeed())
+		ingress = newIngress("team-a/web", "uid-1")
+	})
+
+	Describe("Key and Describe", func() {
+		It("should resolve the source of an AppIngress", func() {
+			key, ok := sourceref.Key(ingress)
+			Expect(ok).To(BeTrue())
+			Expect(key).To(Equal(types.NamespacedName{Namespace: "team-a", Name: "web"}))
+			Expect(sourceref.Describe(key)).To(Equal("AppIngress team-a/web"))
+		})
+
+		It("should resolve the source of a ClusterAppIngress by its name", func() {
+			key, ok := sourceref.Key(newIngress("status-page", "uid-1"))
+			Expect(ok).To(BeTrue())
+			Expect(key).To(Equal(types.NamespacedName{Name: "status-page"}))
+			Expect(sourceref.Describe(key)).To(Equal("ClusterAppIngress status-page"))
+		})
+
+		It("should reject unmanaged objects and invalid annotations", func() {
+			delete(ingress.Labels, ingressv1alpha1.ManagedByLabel)
+			_, ok := sourceref.Key(ingress)
+			Expect(ok).To(BeFalse())
+
+			_, ok = sourceref.Key(newIngress("a/b/c", "uid-1"))
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Get", func() {
+		It("should return the AppIngress or ClusterAppIngress the object was generated from", func() {
+			appIngress := newAppIngress("uid-1")
+			clusterAppIngress := &ingressv1alpha1.ClusterAppIngress{ObjectMeta: metav1.ObjectMeta{Name: "status-page"}}
+			reader := newReader(appIngress, clusterAppIngress)
+
+			source, err := sourceref.Get(ctx, reader, ingress)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(source).To(BeAssignableToTypeOf(&ingressv1alpha1.AppIngress{}))
+			Expect(source.GetUID()).To(Equal(types.UID("uid-1")))
+
+			source, err = sourceref.Get(ctx, reader, newIngress("status-page", ""))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(source).To(BeAssignableToTypeOf(&ingressv1alpha1.ClusterAppIngress{}))
+		})
+
+		It("should fail for objects without a source", func() {
+			delete(ingress.Annotations, ingressv1alpha1.SourceAnnotation)
+			_, err := sourceref.Get(ctx, newReader(), ingress)
+			Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+		})
+	})
+
+	Describe("OrphanReason", func() {
+		It("should not report objects whose source generated them", func() {
+			Expect(sourceref.OrphanReason(ctx, newReader(newAppIngress("uid-1")), ingress)).To(BeEmpty())
+		})
+
+		It("should report objects whose source is gone", func() {
+			Expect(sourceref.OrphanReason(ctx, newReader(), ingress)).To(Equal(sourceref.ReasonSourceNotFound))
+		})
+
+		It("should report objects left behind by a recreated source", func() {
+			Expect(sourceref.OrphanReason(ctx, newReader(newAppIngress("uid-2")), ingress)).
+				To(Equal(sourceref.ReasonSourceRecreated))
+		})
+
+		It("should not report objects the recreated source generates again", func() {
+			appIngress := newAppIngress("uid-2", ingressv1alpha1.ResourceReference{Namespace: "team-b", Name: "web"})
+			Expect(sourceref.Generates(appIngress, ingress)).To(BeTrue())
+			Expect(sourceref.OrphanReason(ctx, newReader(appIngress), ingress)).To(BeEmpty())
+		})
+
+		It("should not report objects the recreated source targets but has not generated yet", func() {
+			appIngress := newAppIngress("uid-2")
+			appIngress.Spec.Template.Name = "web"
+			appIngress.Spec.TargetNamespaces = []string{"team-b"}
+			Expect(sourceref.OrphanReason(ctx, newReader(appIngress), ingress)).To(BeEmpty())
+
+			appIngress.Spec.TargetNamespaces = nil
+			appIngress.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}}
+			namespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"tenant": "true"}},
+			}
+			Expect(sourceref.OrphanReason(ctx, newReader(appIngress, namespace), ingress)).To(BeEmpty())
+
+			namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}}
+			Expect(sourceref.OrphanReason(ctx, newReader(appIngress, namespace), ingress)).
+				To(Equal(sourceref.ReasonSourceRecreated))
+		})
+
+		It("should not report objects generated before the source UID was recorded", func() {
+			delete(ingress.Annotations, ingressv1alpha1.SourceUIDAnnotation)
+			Expect(sourceref.OrphanReason(ctx, newReader(newAppIngress("uid-2")), ingress)).To(BeEmpty())
+		})
+
+		It("should never report objects the controller does not manage", func() {
+			delete(ingress.Labels, ingressv1alpha1.ManagedByLabel)
+			Expect(sourceref.OrphanReason(ctx, newReader(), ingress)).To(BeEmpty())
+		})
+	})
+})
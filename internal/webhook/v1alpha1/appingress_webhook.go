@@ -0,0 +1,502 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/hostclaim"
+	"github.com/rafal-jan/ingress-duplicator/internal/httproute"
+	"github.com/rafal-jan/ingress-duplicator/internal/policy"
+	"github.com/rafal-jan/ingress-duplicator/internal/render"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var appingresslog = logf.Log.WithName("appingress-resource")
+
+// DefaultDeniedNamespaces lists the system namespaces AppIngresses may not target by default
+var DefaultDeniedNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// AppIngressWebhookOptions configures the AppIngress webhooks
+type AppIngressWebhookOptions struct {
+	// DeniedNamespaces lists namespaces AppIngresses may not target
+	DeniedNamespaces []string
+
+	// Defaults are applied to the template of every AppIngress
+	Defaults AppIngressDefaults
+
+	// Policy rejects AppIngresses targeting namespaces or hosts no AppIngressPolicy allows.
+	// Policies are not enforced when it is nil.
+	Policy *policy.Evaluator
+
+	// IncludeUnmanagedHosts also rejects hosts routed by Ingresses not managed by the controller
+	IncludeUnmanagedHosts bool
+
+	// GatewayAPI admits AppIngresses generating HTTPRoutes
+	GatewayAPI bool
+}
+
+// SetupAppIngressWebhookWithManager registers the webhook for AppIngress in the manager.
+func SetupAppIngressWebhookWithManager(mgr ctrl.Manager, opts AppIngressWebhookOptions) error {
+	if err := hostclaim.IndexFields(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).For(&ingressv1alpha1.AppIngress{}).
+		WithValidator(&AppIngressCustomValidator{
+			Client:                mgr.GetClient(),
+			DeniedNamespaces:      opts.DeniedNamespaces,
+			Policy:                opts.Policy,
+			IncludeUnmanagedHosts: opts.IncludeUnmanagedHosts,
+			GatewayAPI:            opts.GatewayAPI,
+			indexedHosts:          true,
+		}).
+		WithDefaulter(&AppIngressCustomDefaulter{
+			Defaults: opts.Defaults,
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-ingress-example-com-v1alpha1-appingress,mutating=true,failurePolicy=fail,sideEffects=None,groups=ingress.example.com,resources=appingresses,verbs=create;update,versions=v1alpha1,name=mappingress-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// AppIngressCustomDefaulter struct is responsible for setting default values on the custom resource of the
+// Kind AppIngress when those are created or updated.
+type AppIngressCustomDefaulter struct {
+	// Defaults are the cluster-wide values applied to the template
+	Defaults AppIngressDefaults
+}
+
+var _ webhook.CustomDefaulter = &AppIngressCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind AppIngress.
+func (d *AppIngressCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	appingress, ok := obj.(*ingressv1alpha1.AppIngress)
+	if !ok {
+		return fmt.Errorf("expected an AppIngress object but got %T", obj)
+	}
+	appingresslog.Info("Defaulting for AppIngress", "name", appingress.GetName())
+
+	// Objects being deleted only need to get their finalizer removed
+	if !appingress.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	template := &appingress.Spec.Template
+	if template.Spec.IngressClassName == nil && d.Defaults.IngressClassName != "" {
+		ingressClassName := d.Defaults.IngressClassName
+		template.Spec.IngressClassName = &ingressClassName
+	}
+
+	if d.Defaults.TLSSecretNamePattern != "" {
+		for i := range template.Spec.TLS {
+			tls := &template.Spec.TLS[i]
+			if tls.SecretName != "" {
+				continue
+			}
+			var host string
+			if len(tls.Hosts) > 0 {
+				host = tls.Hosts[0]
+			}
+			tls.SecretName = d.Defaults.tlsSecretName(template.Name, host)
+		}
+	}
+
+	for key, value := range d.Defaults.Annotations {
+		if _, ok := template.Annotations[key]; ok {
+			continue
+		}
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[key] = value
+	}
+
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	template.Labels[ingressv1alpha1.ManagedByLabel] = ingressv1alpha1.ManagedByValue
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-ingress-example-com-v1alpha1-appingress,mutating=false,failurePolicy=fail,sideEffects=None,groups=ingress.example.com,resources=appingresses,verbs=create;update,versions=v1alpha1,name=vappingress-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// AppIngressCustomValidator struct is responsible for validating the AppIngress resource
+// when it is created or updated.
+type AppIngressCustomValidator struct {
+	// Client is used to look up other AppIngresses for collision checks
+	Client client.Reader
+
+	// DeniedNamespaces lists namespaces AppIngresses may not target
+	DeniedNamespaces []string
+
+	// Policy rejects target namespaces and hosts no AppIngressPolicy allows, nil disables it
+	Policy *policy.Evaluator
+
+	// IncludeUnmanagedHosts also rejects hosts routed by Ingresses not managed by the controller
+	IncludeUnmanagedHosts bool
+
+	// GatewayAPI admits AppIngresses generating HTTPRoutes, which are rejected otherwise
+	GatewayAPI bool
+
+	// indexedHosts looks up host claims through the host index registered with the manager
+	indexedHosts bool
+}
+
+var _ webhook.CustomValidator = &AppIngressCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type AppIngress.
+func (v *AppIngressCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	appingress, ok := obj.(*ingressv1alpha1.AppIngress)
+	if !ok {
+		return nil, fmt.Errorf("expected a AppIngress object but got %T", obj)
+	}
+	appingresslog.Info("Validation for AppIngress upon creation", "name", appingress.GetName())
+
+	return nil, v.validateAppIngress(ctx, appingress)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type AppIngress.
+func (v *AppIngressCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	appingress, ok := newObj.(*ingressv1alpha1.AppIngress)
+	if !ok {
+		return nil, fmt.Errorf("expected a AppIngress object for the newObj but got %T", newObj)
+	}
+	oldAppIngress, ok := oldObj.(*ingressv1alpha1.AppIngress)
+	if !ok {
+		return nil, fmt.Errorf("expected a AppIngress object for the oldObj but got %T", oldObj)
+	}
+	appingresslog.Info("Validation for AppIngress upon update", "name", appingress.GetName())
+
+	// Objects being deleted only need to get their finalizer removed, and updates leaving the spec
+	// unchanged, e.g. adding the finalizer, must not be blocked by objects created since
+	if !appingress.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(oldAppIngress.Spec, appingress.Spec) {
+		return nil, nil
+	}
+	return nil, v.validateAppIngress(ctx, appingress)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type AppIngress.
+func (v *AppIngressCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateAppIngress runs all validations of an AppIngress or ClusterAppIngress and returns them as a
+// single Invalid error. ClusterAppIngresses may bridge backends from any namespace and are not subject
+// to AppIngressPolicies.
+func (v *AppIngressCustomValidator) validateAppIngress(ctx context.Context,
+	appingress ingressv1alpha1.AppIngressObject) error {
+	specPath := field.NewPath("spec")
+	spec := appingress.GetSpec()
+	namespaced, isNamespaced := appingress.(*ingressv1alpha1.AppIngress)
+	kind, article := "ClusterAppIngress", "a"
+	if isNamespaced {
+		kind, article = "AppIngress", "an"
+	}
+
+	var allErrs field.ErrorList
+	templateErrs, err := v.validateRenderedTemplates(ctx, appingress, specPath.Child("template"))
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	allErrs = append(allErrs, templateErrs...)
+	allErrs = append(allErrs, v.validateOutput(spec, specPath)...)
+	allErrs = append(allErrs, validateTargetNamespaces(article+" "+kind, v.DeniedNamespaces, spec.TargetNamespace,
+		spec.TargetNamespaces, specPath)...)
+	if isNamespaced && spec.BackendNamespace != "" && spec.BackendNamespace != namespaced.Namespace {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("backendNamespace"), spec.BackendNamespace,
+			"must be the namespace of the AppIngress"))
+	}
+
+	// Collisions are only meaningful once the object itself is well-formed
+	if len(allErrs) == 0 {
+		collisionErrs, err := v.validateCollisions(ctx, appingress, specPath)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, collisionErrs...)
+
+		hostErrs, err := v.validateHostCollisions(ctx, appingress, specPath)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, hostErrs...)
+	}
+	if len(allErrs) == 0 && v.Policy != nil && isNamespaced {
+		policyErrs, err := v.validatePolicy(ctx, namespaced, specPath)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, policyErrs...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(ingressv1alpha1.GroupVersion.WithKind(kind).GroupKind(),
+		appingress.GetName(), allErrs)
+}
+
+// validateRenderedTemplates renders the template for every explicitly listed target namespace, or
+// for the namespace of the AppIngress when there is none, and validates the results and, for
+// AppIngresses generating HTTPRoutes, their translation. A
+// ClusterAppIngress without one is rendered for the default namespace. Errors shared by several
+// namespaces are reported once.
+func (v *AppIngressCustomValidator) validateRenderedTemplates(ctx context.Context,
+	appingress ingressv1alpha1.AppIngressObject, path *field.Path) (field.ErrorList, error) {
+	namespace := &corev1.Namespace{}
+	if appingress.GetNamespace() != "" {
+		err := v.Client.Get(ctx, client.ObjectKey{Name: appingress.GetNamespace()}, namespace)
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+	}
+	targets := appingress.GetSpec().ExplicitTargetNamespaces()
+	if len(targets) == 0 {
+		targets = []string{cmp.Or(appingress.GetNamespace(), metav1.NamespaceDefault)}
+	}
+
+	var allErrs field.ErrorList
+	add := func(errs field.ErrorList) {
+		for _, err := range errs {
+			if !slices.ContainsFunc(allErrs, func(existing *field.Error) bool {
+				return existing.Error() == err.Error()
+			}) {
+				allErrs = append(allErrs, err)
+			}
+		}
+	}
+	for _, target := range targets {
+		vars := render.NewVariables(appingress, namespace.Labels, target)
+		rendered, renderErrs := render.Template(&appingress.GetSpec().Template, vars, path)
+		add(renderErrs)
+		if len(renderErrs) == 0 {
+			add(validateTemplate(rendered, path))
+			add(v.validateHTTPRoute(appingress.GetSpec(), rendered, path))
+		}
+	}
+	return allErrs, nil
+}
+
+// validateOutput checks that HTTPRoutes are supported when the AppIngress generates them
+func (v *AppIngressCustomValidator) validateOutput(spec *ingressv1alpha1.AppIngressSpec,
+	path *field.Path) field.ErrorList {
+	if spec.OutputKind != ingressv1alpha1.OutputKindHTTPRoute || v.GatewayAPI {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(path.Child("outputKind"),
+		"HTTPRoutes are not supported, the controller runs without --enable-gateway-api")}
+}
+
+// validateHTTPRoute checks that the HTTPRoute generated by the AppIngress can be translated from
+// the template rendered for a target namespace. path is the field path of the template.
+func (v *AppIngressCustomValidator) validateHTTPRoute(spec *ingressv1alpha1.AppIngressSpec,
+	rendered *ingressv1alpha1.IngressTemplate, path *field.Path) field.ErrorList {
+	if spec.OutputKind != ingressv1alpha1.OutputKindHTTPRoute || !v.GatewayAPI {
+		return nil
+	}
+	var parentRefs []gatewayv1.ParentReference
+	if spec.HTTPRoute != nil {
+		parentRefs = spec.HTTPRoute.ParentRefs
+	}
+	_, allErrs := httproute.FromIngress(&rendered.Spec, parentRefs, path.Child("spec"))
+	return allErrs
+}
+
+// validateTemplate checks the Ingress name, hosts and routing rules of the template
+func validateTemplate(template *ingressv1alpha1.IngressTemplate, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	namePath := path.Child("metadata", "name")
+	if template.Name == "" {
+		allErrs = append(allErrs, field.Required(namePath, "Ingress name must be set"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(template.Name) {
+			allErrs = append(allErrs, field.Invalid(namePath, template.Name, msg))
+		}
+	}
+
+	specPath := path.Child("spec")
+	if len(template.Spec.Rules) == 0 && template.Spec.DefaultBackend == nil {
+		allErrs = append(allErrs, field.Required(specPath.Child("rules"),
+			"either rules or a default backend must be specified"))
+	}
+	for i, rule := range template.Spec.Rules {
+		if rule.Host == "" {
+			continue
+		}
+		allErrs = append(allErrs, validateHost(rule.Host, specPath.Child("rules").Index(i).Child("host"))...)
+	}
+	for i, tls := range template.Spec.TLS {
+		for j, host := range tls.Hosts {
+			allErrs = append(allErrs, validateHost(host, specPath.Child("tls").Index(i).Child("hosts").Index(j))...)
+		}
+	}
+	return allErrs
+}
+
+// validateHost checks that host is a DNS name, allowing a single leading wildcard label
+func validateHost(host string, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if strings.HasPrefix(host, "*.") {
+		for _, msg := range validation.IsWildcardDNS1123Subdomain(host) {
+			allErrs = append(allErrs, field.Invalid(path, host, msg))
+		}
+		return allErrs
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(host) {
+		allErrs = append(allErrs, field.Invalid(path, host, msg))
+	}
+	return allErrs
+}
+
+// validateTargetNamespaces checks that the explicitly listed target namespaces of the object, described
+// by kind for messages, are valid namespace names and none of them is denied
+func validateTargetNamespaces(kind string, denied []string, targetNamespace string, targetNamespaces []string,
+	path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	validate := func(namespace string, fldPath *field.Path) {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath, namespace, msg))
+		}
+		if slices.Contains(denied, namespace) {
+			allErrs = append(allErrs, field.Forbidden(fldPath,
+				fmt.Sprintf("namespace %q may not be targeted by %s", namespace, kind)))
+		}
+	}
+
+	if targetNamespace != "" {
+		validate(targetNamespace, path.Child("targetNamespace"))
+	}
+	for i, namespace := range targetNamespaces {
+		validate(namespace, path.Child("targetNamespaces").Index(i))
+	}
+	return allErrs
+}
+
+// validateCollisions rejects AppIngresses and ClusterAppIngresses that would generate an Ingress with
+// the same name in the same namespace as another one of them. Only explicitly listed namespaces are
+// considered, since namespaces matched by a selector change over time.
+func (v *AppIngressCustomValidator) validateCollisions(ctx context.Context,
+	appingress ingressv1alpha1.AppIngressObject, path *field.Path) (field.ErrorList, error) {
+	targets := appingress.GetSpec().ExplicitTargetNamespaces()
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	var allErrs field.ErrorList
+	check := func(owner string, other ingressv1alpha1.AppIngressObject) {
+		name := appingress.GetSpec().Template.Name
+		if other.GetSpec().Template.Name != name {
+			return
+		}
+		for _, namespace := range other.GetSpec().ExplicitTargetNamespaces() {
+			if slices.Contains(targets, namespace) {
+				allErrs = append(allErrs, field.Duplicate(path.Child("template", "metadata", "name"),
+					fmt.Sprintf("Ingress %s/%s is already generated by %s", namespace, name, owner)))
+			}
+		}
+	}
+
+	appIngresses := &ingressv1alpha1.AppIngressList{}
+	if err := v.Client.List(ctx, appIngresses); err != nil {
+		return nil, err
+	}
+	for _, other := range appIngresses.Items {
+		if other.Namespace == appingress.GetNamespace() && other.Name == appingress.GetName() {
+			continue
+		}
+		check("AppIngress "+other.Namespace+"/"+other.Name, &other)
+	}
+	// AppIngresses always have a namespace, so the namespace and name tell the kinds apart
+	clusterAppIngresses := &ingressv1alpha1.ClusterAppIngressList{}
+	if err := v.Client.List(ctx, clusterAppIngresses); err != nil {
+		return nil, err
+	}
+	for _, other := range clusterAppIngresses.Items {
+		if appingress.GetNamespace() == "" && other.Name == appingress.GetName() {
+			continue
+		}
+		check("ClusterAppIngress "+other.Name, &other)
+	}
+	return allErrs, nil
+}
+
+// validateHostCollisions rejects AppIngresses routing a host and path that another AppIngress, or
+// optionally an unmanaged Ingress, already routes. Templated hosts are rendered for the explicitly
+// listed target namespaces. Unlike the controller, which lets the oldest claim win, any collision
+// is rejected so an update cannot take the traffic of a younger claim over.
+func (v *AppIngressCustomValidator) validateHostCollisions(ctx context.Context,
+	appingress ingressv1alpha1.AppIngressObject, path *field.Path) (field.ErrorList, error) {
+	finder := &hostclaim.Finder{Reader: v.Client, IncludeUnmanaged: v.IncludeUnmanagedHosts, Indexed: v.indexedHosts}
+	conflicts, err := finder.Conflicts(ctx, appingress, appingress.GetSpec().ExplicitTargetNamespaces(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	var allErrs field.ErrorList
+	for _, conflict := range conflicts {
+		rulePath := path.Child("template", "spec", "rules").Index(conflict.Rule)
+		allErrs = append(allErrs, field.Duplicate(rulePath.Child("host"),
+			fmt.Sprintf("host %s is already claimed by %s", conflict.Claim, conflict.Owner)))
+	}
+	return allErrs, nil
+}
+
+// validatePolicy rejects explicitly listed target namespaces that no AppIngressPolicy allows for the
+// namespace of the AppIngress. Namespaces matched by a selector are checked by the controller.
+func (v *AppIngressCustomValidator) validatePolicy(ctx context.Context, appingress *ingressv1alpha1.AppIngress,
+	path *field.Path) (field.ErrorList, error) {
+	denials, err := v.Policy.Denials(ctx, appingress, appingress.Spec.ExplicitTargetNamespaces())
+	if err != nil {
+		return nil, err
+	}
+	return denialErrors(denials, appingress.Spec.TargetNamespace, appingress.Spec.TargetNamespaces, path), nil
+}
+
+// denialErrors reports the denied explicitly listed target namespaces, each at the field listing it
+func denialErrors(denials map[string]string, targetNamespace string, targetNamespaces []string,
+	path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if message, ok := denials[targetNamespace]; ok {
+		allErrs = append(allErrs, field.Forbidden(path.Child("targetNamespace"), message))
+	}
+	for i, namespace := range targetNamespaces {
+		if message, ok := denials[namespace]; ok && namespace != targetNamespace {
+			allErrs = append(allErrs, field.Forbidden(path.Child("targetNamespaces").Index(i), message))
+		}
+	}
+	return allErrs
+}
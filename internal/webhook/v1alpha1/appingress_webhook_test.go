@@ -0,0 +1,421 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/policy"
+)
+
+var _ = Describe("AppIngress Webhook", func() {
+	var (
+		obj       *ingressv1alpha1.AppIngress
+		oldObj    *ingressv1alpha1.AppIngress
+		validator AppIngressCustomValidator
+		defaulter AppIngressCustomDefaulter
+	)
+
+	newAppIngress := func(name, targetNamespace string) *ingressv1alpha1.AppIngress {
+		pathType := networkingv1.PathTypePrefix
+		return &ingressv1alpha1.AppIngress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: ingressv1alpha1.AppIngressSpec{
+				Template: ingressv1alpha1.IngressTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-ingress",
+					},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{
+							Host: "app.example.com",
+							IngressRuleValue: networkingv1.IngressRuleValue{
+								HTTP: &networkingv1.HTTPIngressRuleValue{
+									Paths: []networkingv1.HTTPIngressPath{{
+										Path:     "/",
+										PathType: &pathType,
+										Backend: networkingv1.IngressBackend{
+											Service: &networkingv1.IngressServiceBackend{
+												Name: "app",
+												Port: networkingv1.ServiceBackendPort{Number: 80},
+											},
+										},
+									}},
+								},
+							},
+						}},
+					},
+				},
+				TargetNamespace: targetNamespace,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		obj = newAppIngress("test-appingress", "team-a")
+		oldObj = obj.DeepCopy()
+		validator = AppIngressCustomValidator{
+			Client:           k8sClient,
+			DeniedNamespaces: DefaultDeniedNamespaces,
+		}
+		defaulter = AppIngressCustomDefaulter{
+			Defaults: AppIngressDefaults{
+				IngressClassName:     "nginx",
+				TLSSecretNamePattern: "{name}-{host}-tls",
+				Annotations:          map[string]string{"example.com/team": "platform"},
+			},
+		}
+	})
+
+	Context("When creating AppIngress under Defaulting Webhook", func() {
+		It("Should apply the configured defaults to the template", func() {
+			obj.Spec.Template.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"*.example.com"}}}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			template := obj.Spec.Template
+			Expect(template.Spec.IngressClassName).NotTo(BeNil())
+			Expect(*template.Spec.IngressClassName).To(Equal("nginx"))
+			Expect(template.Spec.TLS[0].SecretName).To(Equal("test-ingress-wildcard-example-com-tls"))
+			Expect(template.Annotations).To(HaveKeyWithValue("example.com/team", "platform"))
+			Expect(template.Labels).To(HaveKeyWithValue(ingressv1alpha1.ManagedByLabel, ingressv1alpha1.ManagedByValue))
+		})
+
+		It("Should not override values set in the template", func() {
+			ingressClassName := "traefik"
+			obj.Spec.Template.Spec.IngressClassName = &ingressClassName
+			obj.Spec.Template.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"app.example.com"}, SecretName: "custom"}}
+			obj.Spec.Template.Annotations = map[string]string{"example.com/team": "payments"}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			template := obj.Spec.Template
+			Expect(*template.Spec.IngressClassName).To(Equal("traefik"))
+			Expect(template.Spec.TLS[0].SecretName).To(Equal("custom"))
+			Expect(template.Annotations).To(HaveKeyWithValue("example.com/team", "payments"))
+		})
+
+		It("Should load defaults from a ConfigMap on top of the flag values", func() {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "appingress-defaults", Namespace: "default"},
+				Data: map[string]string{
+					DefaultsIngressClassNameKey: "haproxy",
+					DefaultsAnnotationsKey:      "example.com/owner=platform\nexample.com/list=a,b",
+				},
+			}
+			Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+			})
+
+			defaults, err := LoadDefaultsFromConfigMap(ctx, k8sClient, client.ObjectKeyFromObject(configMap),
+				defaulter.Defaults)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(defaults.IngressClassName).To(Equal("haproxy"))
+			Expect(defaults.TLSSecretNamePattern).To(Equal("{name}-{host}-tls"))
+			Expect(defaults.Annotations).To(Equal(map[string]string{
+				"example.com/team":  "platform",
+				"example.com/owner": "platform",
+				"example.com/list":  "a,b",
+			}))
+		})
+	})
+
+	Context("When creating or updating AppIngress under Validating Webhook", func() {
+		It("Should admit a well-formed AppIngress", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny creation if the Ingress name is missing", func() {
+			obj.Spec.Template.Name = ""
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.metadata.name"))
+		})
+
+		It("Should deny creation if a host is not a valid DNS name", func() {
+			obj.Spec.Template.Spec.Rules[0].Host = "Not_A_Host"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec.rules[0].host"))
+		})
+
+		It("Should admit a wildcard host", func() {
+			obj.Spec.Template.Spec.Rules[0].Host = "*.example.com"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should validate templated values after rendering them", func() {
+			obj.Spec.Parameters = map[string]string{"domain": "example.com"}
+			obj.Spec.Template.Spec.Rules[0].Host = "{{ .TargetNamespace }}.{{ .Parameters.domain }}"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			By("denying templates that do not render")
+			obj.Spec.Template.Spec.Rules[0].Host = "{{ .TargetNamespace }}.{{ .Parameters.missing }}"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec.rules[0].host"))
+
+			By("denying templates that render into an invalid host")
+			obj.Spec.Template.Spec.Rules[0].Host = "{{ .Parameters.domain }}_"
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec.rules[0].host"))
+		})
+
+		It("Should only admit HTTPRoutes that can be translated from the template", func() {
+			obj.Spec.OutputKind = ingressv1alpha1.OutputKindHTTPRoute
+			obj.Spec.HTTPRoute = &ingressv1alpha1.HTTPRouteOutput{
+				ParentRefs: []gatewayv1.ParentReference{{Name: "shared-gateway"}},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.outputKind"))
+
+			validator.GatewayAPI = true
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			By("denying backends referring to a port by name")
+			obj.Spec.Template.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port = networkingv1.ServiceBackendPort{Name: "http"}
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec.rules[0].http.paths[0].backend.service.port.name"))
+
+			By("translating the template rendered for the target namespace")
+			obj.Spec.Template.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port = networkingv1.ServiceBackendPort{Number: 80}
+			obj.Spec.Template.Spec.Rules[0].HTTP.Paths[0].Path = "/{{ .TargetNamespace }}"
+			second := *obj.Spec.Template.Spec.Rules[0].DeepCopy()
+			second.Host = "other.example.com"
+			second.HTTP.Paths[0].Path = "/{{.TargetNamespace}}"
+			obj.Spec.Template.Spec.Rules = append(obj.Spec.Template.Spec.Rules, second)
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny creation if neither rules nor a default backend are set", func() {
+			obj.Spec.Template.Spec.Rules = nil
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec.rules"))
+		})
+
+		It("Should deny targeting a denied namespace", func() {
+			obj.Spec.TargetNamespaces = []string{"kube-system"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.targetNamespaces[0]"))
+		})
+
+		It("Should only admit bridging backends from the namespace of the AppIngress", func() {
+			obj.Spec.BackendNamespace = "default"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.BackendNamespace = "team-b"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.backendNamespace"))
+		})
+
+		It("Should deny updates that introduce an invalid template", func() {
+			obj.Spec.Template.Name = "Invalid_Name"
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+
+		It("Should admit updates that leave the spec unchanged or delete the AppIngress", func() {
+			obj.Spec.TargetNamespaces = []string{"kube-system"}
+			oldObj = obj.DeepCopy()
+			obj.Finalizers = []string{"ingress.example.com/finalizer"}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+
+			By("admitting any update to an AppIngress being deleted")
+			obj.Spec.Template.Name = "Invalid_Name"
+			obj.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny an AppIngress generating the same Ingress as another one", func() {
+			existing := newAppIngress("existing-appingress", "team-b")
+			existing.Spec.Template.Spec.Rules[0].Host = "other.example.com"
+			Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, existing)).To(Succeed())
+			})
+
+			obj.Spec.TargetNamespace = ""
+			obj.Spec.TargetNamespaces = []string{"team-a", "team-b"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("existing-appingress"))
+
+			By("admitting the same Ingress name in a different namespace")
+			obj.Spec.TargetNamespaces = []string{"team-a"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny an AppIngress routing the same host and path as another one", func() {
+			existing := newAppIngress("existing-appingress", "team-b")
+			existing.Spec.Template.Name = "existing-ingress"
+			Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, existing)).To(Succeed())
+			})
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec.rules[0].host"))
+			Expect(err.Error()).To(ContainSubstring("AppIngress default/existing-appingress"))
+
+			By("admitting the same host with a different path")
+			obj.Spec.Template.Spec.Rules[0].HTTP.Paths[0].Path = "/api"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should render templated hosts before checking them for collisions and policies", func() {
+			existing := newAppIngress("existing-appingress", "team-b")
+			existing.Spec.Template.Name = "existing-ingress"
+			existing.Spec.Template.Spec.Rules[0].Host = "team-b.example.com"
+			Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, existing)).To(Succeed())
+			})
+			obj.Spec.Template.Spec.Rules[0].Host = "{{ .TargetNamespace }}.example.com"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			By("denying a host that renders into the host of another AppIngress")
+			obj.Spec.TargetNamespaces = []string{"team-b"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec.rules[0].host"))
+			Expect(err.Error()).To(ContainSubstring("team-b.example.com/"))
+
+			By("denying a host that renders into a host no AppIngressPolicy allows")
+			appIngressPolicy := &ingressv1alpha1.AppIngressPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "default-to-team-a-host"},
+				Spec: ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaces: []string{"default"},
+					TargetNamespaces: []string{"team-a", "team-c"},
+					Hostnames:        []string{"team-a.example.com"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, appIngressPolicy)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, appIngressPolicy)).To(Succeed())
+			})
+			validator.Policy = &policy.Evaluator{Reader: k8sClient}
+			obj.Spec.TargetNamespaces = []string{"team-c"}
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("team-c.example.com"))
+		})
+
+		It("Should deny hosts routed by unmanaged Ingresses when asked to", func() {
+			pathType := networkingv1.PathTypePrefix
+			unmanaged := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: "default"},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{{
+						Host: "app.example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "legacy",
+											Port: networkingv1.ServiceBackendPort{Number: 80},
+										},
+									},
+								}},
+							},
+						},
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, unmanaged)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, unmanaged)).To(Succeed())
+			})
+
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			validator.IncludeUnmanagedHosts = true
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("Ingress default/unmanaged"))
+
+			By("admitting AppIngresses that would adopt the unmanaged Ingress")
+			obj.Spec.TargetNamespace = "default"
+			obj.Spec.Template.Name = unmanaged.Name
+			obj.Spec.AdoptionPolicy = ingressv1alpha1.AdoptionPolicyIfUnowned
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.AdoptionPolicy = ingressv1alpha1.AdoptionPolicyNever
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+
+		It("Should deny targets and hosts no AppIngressPolicy allows when policies are enforced", func() {
+			appIngressPolicy := &ingressv1alpha1.AppIngressPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "default-to-team-a"},
+				Spec: ingressv1alpha1.AppIngressPolicySpec{
+					SourceNamespaces: []string{"default"},
+					TargetNamespaces: []string{"team-a"},
+					Hostnames:        []string{"*.example.com"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, appIngressPolicy)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, appIngressPolicy)).To(Succeed())
+			})
+			validator.Policy = &policy.Evaluator{Reader: k8sClient}
+
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			By("denying a namespace the policy does not list")
+			obj.Spec.TargetNamespaces = []string{"team-b"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.targetNamespaces[0]"))
+
+			By("denying a host the policy does not list")
+			obj.Spec.TargetNamespaces = nil
+			obj.Spec.Template.Spec.Rules[0].Host = "app.example.org"
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("app.example.org"))
+		})
+
+		It("Should reject invalid objects through the API server", func() {
+			obj.Spec.TargetNamespace = "kube-system"
+			err := k8sClient.Create(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,104 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/hostclaim"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var clusterappingresslog = logf.Log.WithName("clusterappingress-resource")
+
+// SetupClusterAppIngressWebhookWithManager registers the webhook for ClusterAppIngress in the manager.
+// It shares the options of the AppIngress webhooks, policies do not apply to ClusterAppIngresses.
+func SetupClusterAppIngressWebhookWithManager(mgr ctrl.Manager, opts AppIngressWebhookOptions) error {
+	if err := hostclaim.IndexFields(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).For(&ingressv1alpha1.ClusterAppIngress{}).
+		WithValidator(&ClusterAppIngressCustomValidator{
+			AppIngressCustomValidator: AppIngressCustomValidator{
+				Client:                mgr.GetClient(),
+				DeniedNamespaces:      opts.DeniedNamespaces,
+				IncludeUnmanagedHosts: opts.IncludeUnmanagedHosts,
+				GatewayAPI:            opts.GatewayAPI,
+				indexedHosts:          true,
+			},
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-ingress-example-com-v1alpha1-clusterappingress,mutating=false,failurePolicy=fail,sideEffects=None,groups=ingress.example.com,resources=clusterappingresses,verbs=create;update,versions=v1alpha1,name=vclusterappingress-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ClusterAppIngressCustomValidator struct is responsible for validating the ClusterAppIngress
+// resource when it is created or updated. It runs the checks of the AppIngress validator.
+type ClusterAppIngressCustomValidator struct {
+	AppIngressCustomValidator
+}
+
+var _ webhook.CustomValidator = &ClusterAppIngressCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ClusterAppIngress.
+func (v *ClusterAppIngressCustomValidator) ValidateCreate(ctx context.Context,
+	obj runtime.Object) (admission.Warnings, error) {
+	clusterappingress, ok := obj.(*ingressv1alpha1.ClusterAppIngress)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterAppIngress object but got %T", obj)
+	}
+	clusterappingresslog.Info("Validation for ClusterAppIngress upon creation", "name", clusterappingress.GetName())
+
+	return nil, v.validateAppIngress(ctx, clusterappingress)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ClusterAppIngress.
+func (v *ClusterAppIngressCustomValidator) ValidateUpdate(ctx context.Context,
+	oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	clusterappingress, ok := newObj.(*ingressv1alpha1.ClusterAppIngress)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterAppIngress object for the newObj but got %T", newObj)
+	}
+	oldClusterAppIngress, ok := oldObj.(*ingressv1alpha1.ClusterAppIngress)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterAppIngress object for the oldObj but got %T", oldObj)
+	}
+	clusterappingresslog.Info("Validation for ClusterAppIngress upon update", "name", clusterappingress.GetName())
+
+	// Objects being deleted only need to get their finalizer removed, and updates leaving the spec
+	// unchanged are not validated again
+	if !clusterappingress.DeletionTimestamp.IsZero() ||
+		equality.Semantic.DeepEqual(oldClusterAppIngress.Spec, clusterappingress.Spec) {
+		return nil, nil
+	}
+	return nil, v.validateAppIngress(ctx, clusterappingress)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ClusterAppIngress.
+func (v *ClusterAppIngressCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
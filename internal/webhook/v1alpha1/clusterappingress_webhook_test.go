@@ -0,0 +1,152 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+var _ = Describe("ClusterAppIngress Webhook", func() {
+	var (
+		obj       *ingressv1alpha1.ClusterAppIngress
+		validator ClusterAppIngressCustomValidator
+	)
+
+	BeforeEach(func() {
+		pathType := networkingv1.PathTypePrefix
+		obj = &ingressv1alpha1.ClusterAppIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-clusterappingress"},
+			Spec: ingressv1alpha1.AppIngressSpec{
+				Template: ingressv1alpha1.IngressTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "platform-ingress"},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{
+							Host: "platform.example.com",
+							IngressRuleValue: networkingv1.IngressRuleValue{
+								HTTP: &networkingv1.HTTPIngressRuleValue{
+									Paths: []networkingv1.HTTPIngressPath{{
+										Path:     "/",
+										PathType: &pathType,
+										Backend: networkingv1.IngressBackend{
+											Service: &networkingv1.IngressServiceBackend{
+												Name: "platform",
+												Port: networkingv1.ServiceBackendPort{Number: 80},
+											},
+										},
+									}},
+								},
+							},
+						}},
+					},
+				},
+				TargetNamespace:  "team-a",
+				BackendNamespace: "platform",
+			},
+		}
+		validator = ClusterAppIngressCustomValidator{
+			AppIngressCustomValidator: AppIngressCustomValidator{
+				Client:           k8sClient,
+				DeniedNamespaces: DefaultDeniedNamespaces,
+			},
+		}
+	})
+
+	Context("When creating or updating ClusterAppIngress under Validating Webhook", func() {
+		It("Should admit a well-formed ClusterAppIngress bridging backends from any namespace", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny templates that do not render", func() {
+			obj.Spec.Template.Spec.Rules[0].Host = "{{ .Parameters.missing }}.example.com"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec.rules[0].host"))
+		})
+
+		It("Should deny targeting a denied namespace", func() {
+			obj.Spec.TargetNamespaces = []string{"kube-system"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.targetNamespaces[0]"))
+
+			By("only validating updates that change the spec")
+			oldObj := obj.DeepCopy()
+			obj.Labels = map[string]string{"team": "platform"}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+			obj.Spec.TargetNamespace = "team-b"
+			_, err = validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+
+		It("Should deny generating the same Ingress or routing the same host as an AppIngress", func() {
+			existing := obj.DeepCopy()
+			appIngress := &ingressv1alpha1.AppIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "existing-appingress", Namespace: "default"},
+				Spec:       existing.Spec,
+			}
+			appIngress.Spec.BackendNamespace = ""
+			appIngress.Spec.Template.Spec.Rules[0].Host = "other.example.com"
+			Expect(k8sClient.Create(ctx, appIngress)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, appIngress)).To(Succeed())
+			})
+
+			By("denying the same Ingress name in the same namespace")
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.metadata.name"))
+			Expect(err.Error()).To(ContainSubstring("AppIngress default/existing-appingress"))
+
+			By("denying the same host and path")
+			obj.Spec.Template.Name = "other-ingress"
+			obj.Spec.Template.Spec.Rules[0].Host = "other.example.com"
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec.rules[0].host"))
+		})
+
+		It("Should keep AppIngresses from generating the Ingress of a ClusterAppIngress", func() {
+			Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, obj)).To(Succeed())
+			})
+
+			appIngress := &ingressv1alpha1.AppIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "rival-appingress", Namespace: "default"},
+				Spec:       *obj.Spec.DeepCopy(),
+			}
+			appIngress.Spec.BackendNamespace = ""
+			appIngress.Spec.Template.Spec.Rules[0].Host = "rival.example.com"
+			_, err := validator.AppIngressCustomValidator.ValidateCreate(ctx, appIngress)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("ClusterAppIngress " + obj.Name))
+		})
+
+		It("Should reject invalid objects through the API server", func() {
+			obj.Spec.TargetNamespace = "kube-system"
+			err := k8sClient.Create(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+})
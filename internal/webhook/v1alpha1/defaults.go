@@ -0,0 +1,115 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Keys read from the defaults ConfigMap
+const (
+	// DefaultsIngressClassNameKey holds the ingress class set on templates without one
+	DefaultsIngressClassNameKey = "ingressClassName"
+
+	// DefaultsTLSSecretNamePatternKey holds the pattern used to name TLS secrets left empty in templates
+	DefaultsTLSSecretNamePatternKey = "tlsSecretNamePattern"
+
+	// DefaultsAnnotationsKey holds the key=value annotations added to templates, one per line
+	DefaultsAnnotationsKey = "annotations"
+)
+
+// AppIngressDefaults holds the cluster-wide defaults applied to AppIngress templates
+type AppIngressDefaults struct {
+	// IngressClassName is set on templates that do not specify an ingress class
+	IngressClassName string
+
+	// TLSSecretNamePattern names the secret of TLS entries without one. The "{name}" placeholder
+	// is replaced with the Ingress name and "{host}" with the first host of the entry.
+	TLSSecretNamePattern string
+
+	// Annotations are added to the template unless it already sets them
+	Annotations map[string]string
+}
+
+// ParseAnnotations parses a comma-separated list of key=value pairs
+func ParseAnnotations(value string) (map[string]string, error) {
+	return parseAnnotations(strings.Split(value, ","))
+}
+
+// parseAnnotations parses key=value pairs, skipping empty ones
+func parseAnnotations(pairs []string) (map[string]string, error) {
+	annotations := map[string]string{}
+	for _, pair := range pairs {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid annotation %q, expected key=value", pair)
+		}
+		annotations[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return annotations, nil
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+
+// LoadDefaultsFromConfigMap overlays the values found in the given ConfigMap on top of defaults.
+// Keys missing from the ConfigMap keep the values passed in.
+func LoadDefaultsFromConfigMap(ctx context.Context, reader client.Reader, key types.NamespacedName,
+	defaults AppIngressDefaults) (AppIngressDefaults, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, key, configMap); err != nil {
+		return defaults, err
+	}
+
+	if value, ok := configMap.Data[DefaultsIngressClassNameKey]; ok {
+		defaults.IngressClassName = strings.TrimSpace(value)
+	}
+	if value, ok := configMap.Data[DefaultsTLSSecretNamePatternKey]; ok {
+		defaults.TLSSecretNamePattern = strings.TrimSpace(value)
+	}
+	if value, ok := configMap.Data[DefaultsAnnotationsKey]; ok {
+		// One pair per line, so values may contain commas
+		annotations, err := parseAnnotations(strings.Split(value, "\n"))
+		if err != nil {
+			return defaults, fmt.Errorf("failed to parse %q in ConfigMap %s: %w", DefaultsAnnotationsKey, key, err)
+		}
+		merged := make(map[string]string, len(defaults.Annotations)+len(annotations))
+		for k, v := range defaults.Annotations {
+			merged[k] = v
+		}
+		for k, v := range annotations {
+			merged[k] = v
+		}
+		defaults.Annotations = merged
+	}
+	return defaults, nil
+}
+
+// tlsSecretName renders the TLS secret name pattern for the given Ingress name and host
+func (d *AppIngressDefaults) tlsSecretName(name, host string) string {
+	host = strings.Replace(host, "*", "wildcard", 1)
+	host = strings.ReplaceAll(host, ".", "-")
+	return strings.NewReplacer("{name}", name, "{host}", host).Replace(d.TLSSecretNamePattern)
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+	"github.com/rafal-jan/ingress-duplicator/internal/policy"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var resourceduplicatorlog = logf.Log.WithName("resourceduplicator-resource")
+
+// ResourceDuplicatorWebhookOptions configures the ResourceDuplicator webhook
+type ResourceDuplicatorWebhookOptions struct {
+	// DeniedNamespaces lists namespaces ResourceDuplicators may not target
+	DeniedNamespaces []string
+
+	// AllowedKinds are the kinds ResourceDuplicators may create
+	AllowedKinds []schema.GroupVersionKind
+
+	// Policy rejects ResourceDuplicators targeting namespaces no AppIngressPolicy allows.
+	// Policies are not enforced when it is nil.
+	Policy *policy.Evaluator
+}
+
+// SetupResourceDuplicatorWebhookWithManager registers the webhook for ResourceDuplicator in the manager.
+func SetupResourceDuplicatorWebhookWithManager(mgr ctrl.Manager, opts ResourceDuplicatorWebhookOptions) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&ingressv1alpha1.ResourceDuplicator{}).
+		WithValidator(&ResourceDuplicatorCustomValidator{
+			DeniedNamespaces: opts.DeniedNamespaces,
+			AllowedKinds:     opts.AllowedKinds,
+			Policy:           opts.Policy,
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-ingress-example-com-v1alpha1-resourceduplicator,mutating=false,failurePolicy=fail,sideEffects=None,groups=ingress.example.com,resources=resourceduplicators,verbs=create;update,versions=v1alpha1,name=vresourceduplicator-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ResourceDuplicatorCustomValidator struct is responsible for validating the ResourceDuplicator
+// resource when it is created or updated.
+type ResourceDuplicatorCustomValidator struct {
+	// DeniedNamespaces lists namespaces ResourceDuplicators may not target
+	DeniedNamespaces []string
+
+	// AllowedKinds are the kinds ResourceDuplicators may create, any kind is accepted when it is empty
+	AllowedKinds []schema.GroupVersionKind
+
+	// Policy rejects target namespaces no AppIngressPolicy allows, nil disables it
+	Policy *policy.Evaluator
+}
+
+var _ webhook.CustomValidator = &ResourceDuplicatorCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ResourceDuplicator.
+func (v *ResourceDuplicatorCustomValidator) ValidateCreate(ctx context.Context,
+	obj runtime.Object) (admission.Warnings, error) {
+	duplicator, ok := obj.(*ingressv1alpha1.ResourceDuplicator)
+	if !ok {
+		return nil, fmt.Errorf("expected a ResourceDuplicator object but got %T", obj)
+	}
+	resourceduplicatorlog.Info("Validation for ResourceDuplicator upon creation", "name", duplicator.GetName())
+
+	return v.validateResourceDuplicator(ctx, duplicator)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ResourceDuplicator.
+func (v *ResourceDuplicatorCustomValidator) ValidateUpdate(ctx context.Context,
+	oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	duplicator, ok := newObj.(*ingressv1alpha1.ResourceDuplicator)
+	if !ok {
+		return nil, fmt.Errorf("expected a ResourceDuplicator object for the newObj but got %T", newObj)
+	}
+	oldDuplicator, ok := oldObj.(*ingressv1alpha1.ResourceDuplicator)
+	if !ok {
+		return nil, fmt.Errorf("expected a ResourceDuplicator object for the oldObj but got %T", oldObj)
+	}
+	resourceduplicatorlog.Info("Validation for ResourceDuplicator upon update", "name", duplicator.GetName())
+
+	// Objects being deleted only need to get their finalizer removed, and updates leaving the spec
+	// unchanged are not validated again
+	if !duplicator.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(oldDuplicator.Spec, duplicator.Spec) {
+		return nil, nil
+	}
+	return v.validateResourceDuplicator(ctx, duplicator)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ResourceDuplicator.
+func (v *ResourceDuplicatorCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateResourceDuplicator runs all validations and returns them as a single Invalid error
+func (v *ResourceDuplicatorCustomValidator) validateResourceDuplicator(ctx context.Context,
+	duplicator *ingressv1alpha1.ResourceDuplicator) (admission.Warnings, error) {
+	specPath := field.NewPath("spec")
+
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, v.validateTemplate(duplicator.Spec.Template, specPath.Child("template"))...)
+	allErrs = append(allErrs, validateTargetNamespaces("a ResourceDuplicator", v.DeniedNamespaces,
+		duplicator.Spec.TargetNamespace, duplicator.Spec.TargetNamespaces, specPath)...)
+	if len(allErrs) == 0 && v.Policy != nil {
+		denials, err := v.Policy.NamespaceDenials(ctx, duplicator.Namespace, duplicator.Spec.ExplicitTargetNamespaces())
+		if err != nil {
+			return nil, apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, denialErrors(denials, duplicator.Spec.TargetNamespace,
+			duplicator.Spec.TargetNamespaces, specPath)...)
+	}
+	if len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(ingressv1alpha1.GroupVersion.WithKind("ResourceDuplicator").GroupKind(),
+			duplicator.Name, allErrs)
+	}
+
+	var warnings admission.Warnings
+	policy := duplicator.Spec.AdoptionPolicy
+	if policy != "" && policy != ingressv1alpha1.AdoptionPolicyNever && slices.ContainsFunc(
+		duplicator.Spec.ExplicitTargetNamespaces(), func(namespace string) bool { return namespace != duplicator.Namespace }) {
+		warnings = append(warnings, fmt.Sprintf("adoptionPolicy %s only applies in namespace %s, "+
+			"existing objects in other namespaces are never adopted", policy, duplicator.Namespace))
+	}
+	return warnings, nil
+}
+
+// validateTemplate checks that the template is an object of an allowed kind with a valid name that
+// does not carry the annotations the controller tracks its objects with
+func (v *ResourceDuplicatorCustomValidator) validateTemplate(raw runtime.RawExtension,
+	path *field.Path) field.ErrorList {
+	template := &unstructured.Unstructured{}
+	if err := template.UnmarshalJSON(raw.Raw); err != nil {
+		return field.ErrorList{field.Invalid(path, string(raw.Raw), "must be a Kubernetes object: "+err.Error())}
+	}
+
+	var allErrs field.ErrorList
+	gvk := template.GroupVersionKind()
+	if len(v.AllowedKinds) > 0 && !slices.Contains(v.AllowedKinds, gvk) {
+		allowed := make([]string, 0, len(v.AllowedKinds))
+		for _, kind := range v.AllowedKinds {
+			allowed = append(allowed, kind.GroupVersion().String()+"/"+kind.Kind)
+		}
+		allErrs = append(allErrs, field.NotSupported(path.Child("kind"), gvk.GroupVersion().String()+"/"+gvk.Kind,
+			allowed))
+	}
+
+	namePath := path.Child("metadata", "name")
+	if template.GetName() == "" {
+		allErrs = append(allErrs, field.Required(namePath, "object name must be set"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(template.GetName()) {
+			allErrs = append(allErrs, field.Invalid(namePath, template.GetName(), msg))
+		}
+	}
+	// Templates may not pass their objects off as generated from someone else
+	for _, key := range slices.Sorted(maps.Keys(template.GetAnnotations())) {
+		if slices.Contains(ingressv1alpha1.MarkerAnnotations, key) {
+			allErrs = append(allErrs, field.Forbidden(path.Child("metadata", "annotations").Key(key),
+				"is set by the controller"))
+		}
+	}
+	return allErrs
+}
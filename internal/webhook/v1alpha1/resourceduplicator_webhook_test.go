@@ -0,0 +1,98 @@
+/*
+Copyright 2025 Rafal Jan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	ingressv1alpha1 "github.com/rafal-jan/ingress-duplicator/api/v1alpha1"
+)
+
+var _ = Describe("ResourceDuplicator Webhook", func() {
+	var (
+		obj       *ingressv1alpha1.ResourceDuplicator
+		validator ResourceDuplicatorCustomValidator
+	)
+
+	setTemplate := func(template string) {
+		obj.Spec.Template = runtime.RawExtension{Raw: []byte(template)}
+	}
+
+	BeforeEach(func() {
+		obj = &ingressv1alpha1.ResourceDuplicator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-duplicator",
+				Namespace: "default",
+			},
+			Spec: ingressv1alpha1.ResourceDuplicatorSpec{
+				TargetNamespace: "team-a",
+			},
+		}
+		setTemplate(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"shared-config"}}`)
+		validator = ResourceDuplicatorCustomValidator{
+			DeniedNamespaces: DefaultDeniedNamespaces,
+			AllowedKinds:     []schema.GroupVersionKind{{Version: "v1", Kind: "ConfigMap"}},
+		}
+	})
+
+	Context("When creating or updating ResourceDuplicator under Validating Webhook", func() {
+		It("Should admit a valid ResourceDuplicator", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny denied target namespaces", func() {
+			obj.Spec.TargetNamespaces = []string{"team-b", "kube-system"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.targetNamespaces[1]"))
+		})
+
+		It("Should deny kinds that are not allowed", func() {
+			setTemplate(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"copied-secret"}}`)
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template.kind"))
+		})
+
+		It("Should deny templates carrying the controller's annotations", func() {
+			setTemplate(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"shared-config",` +
+				`"annotations":{"` + ingressv1alpha1.SourceAnnotation + `":"default/other"}}}`)
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(ingressv1alpha1.SourceAnnotation))
+		})
+
+		It("Should warn when adoption cannot apply to other namespaces", func() {
+			obj.Spec.AdoptionPolicy = ingressv1alpha1.AdoptionPolicyIfUnowned
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+		})
+
+		It("Should reject invalid objects through the API server", func() {
+			obj.Spec.TargetNamespace = "kube-system"
+			err := k8sClient.Create(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+})